@@ -2,6 +2,9 @@ package main
 
 import (
 	"log"
+	// Embedded zone database, so timezone validation works on hosts
+	// without one
+	_ "time/tzdata"
 
 	"github.com/AyoubTahir/projects_management/config"
 	"github.com/AyoubTahir/projects_management/internal/app"
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/AyoubTahir/projects_management/config"
+	_ "github.com/AyoubTahir/projects_management/migrations"
+	"github.com/AyoubTahir/projects_management/pkg/database"
+	"github.com/AyoubTahir/projects_management/pkg/migrations"
+	"github.com/AyoubTahir/projects_management/pkg/orm"
+)
+
+const usage = "usage: migrate up | down [steps] | status"
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	dialect, err := orm.DialectFor(cfg.OrmConfig.Dialect)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	migrator, err := migrations.NewMigrator(db, dialect, migrations.Registered())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "up":
+		ran, err := migrator.Up(ctx)
+		report("Migrated", ran)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			if steps, err = strconv.Atoi(os.Args[2]); err != nil || steps < 1 {
+				log.Fatal(usage)
+			}
+		}
+		ran, err := migrator.Down(ctx, steps)
+		report("Rolled back", ran)
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	default:
+		log.Fatal(usage)
+	}
+}
+
+func report(action string, ran []migrations.Migration) {
+	if len(ran) == 0 {
+		fmt.Println("Nothing to do")
+		return
+	}
+	for _, migration := range ran {
+		fmt.Printf("%s: %d_%s\n", action, migration.Version, migration.Name)
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -13,20 +14,29 @@ type Config struct {
 	Database  DatabaseConfig
 	Logger    LoggerConfig
 	OrmConfig OrmConfig
+	Cache     CacheConfig
 }
 
 type ServerConfig struct {
-	Port    string
-	Timeout int
+	Port      string
+	Timeout   int
+	ReusePort bool
+	// AdminToken enables the /admin endpoints for requests sending it in
+	// the X-Admin-Token header
+	AdminToken string
+	// JSONNaming is the case of response field names: camel (default) or
+	// snake
+	JSONNaming string
 }
 
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	Username string
-	Password string
-	DBName   string
-	SSLMode  string
+	Host         string
+	Port         string
+	Username     string
+	Password     string
+	DBName       string
+	SSLMode      string
+	ReplicaHosts []string
 }
 
 type LoggerConfig struct {
@@ -34,11 +44,30 @@ type LoggerConfig struct {
 	File  string
 }
 
+// CacheConfig selects the ORM query cache backend: "memory", "redis" or
+// empty to disable caching
+type CacheConfig struct {
+	Driver        string
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+}
+
 type OrmConfig struct {
-	MaxOpenConns    int
-	MaxIdleConns    int
-	ConnMaxLifetime time.Duration
-	QueryLog        bool
+	MaxOpenConns        int
+	MaxIdleConns        int
+	ConnMaxLifetime     time.Duration
+	QueryLog            bool
+	StmtCacheSize       int
+	Dialect             string
+	DefaultQueryTimeout time.Duration
+	SlowQueryThreshold  time.Duration
+	Tracing             bool
+	MaxRetries          int
+	RetryBaseDelay      time.Duration
+	DryRun              bool
+	PingInterval        time.Duration
+	ConnAcquireTimeout  time.Duration
 }
 
 func Load() (*Config, error) {
@@ -54,8 +83,11 @@ func Load() (*Config, error) {
 	}
 
 	serverConfig := ServerConfig{
-		Port:    os.Getenv("PORT"),
-		Timeout: timeout,
+		Port:       os.Getenv("PORT"),
+		Timeout:    timeout,
+		ReusePort:  os.Getenv("SERVER_REUSE_PORT") == "true",
+		AdminToken: os.Getenv("ADMIN_TOKEN"),
+		JSONNaming: os.Getenv("JSON_NAMING"),
 	}
 
 	databaseConfig := DatabaseConfig{
@@ -67,16 +99,44 @@ func Load() (*Config, error) {
 		SSLMode:  os.Getenv("DB_SSLMODE"),
 	}
 
+	if replicas := os.Getenv("DB_REPLICA_HOSTS"); replicas != "" {
+		for _, host := range strings.Split(replicas, ",") {
+			databaseConfig.ReplicaHosts = append(databaseConfig.ReplicaHosts, strings.TrimSpace(host))
+		}
+	}
+
 	loggerConfig := LoggerConfig{
 		Level: os.Getenv("LOGGER_LEVEL"),
 		File:  os.Getenv("LOGGER_FILE"),
 	}
 
 	ormConfig := OrmConfig{
-		MaxOpenConns:    20,
-		MaxIdleConns:    5,
-		ConnMaxLifetime: time.Hour,
-		QueryLog:        true,
+		MaxOpenConns:        20,
+		MaxIdleConns:        5,
+		ConnMaxLifetime:     time.Hour,
+		QueryLog:            true,
+		StmtCacheSize:       500,
+		Dialect:             "postgres",
+		DefaultQueryTimeout: 30 * time.Second,
+		SlowQueryThreshold:  500 * time.Millisecond,
+		Tracing:             os.Getenv("ORM_TRACING") == "true",
+		MaxRetries:          3,
+		RetryBaseDelay:      50 * time.Millisecond,
+		DryRun:              os.Getenv("ORM_DRY_RUN") == "true",
+		PingInterval:        30 * time.Second,
+		ConnAcquireTimeout:  5 * time.Second,
+	}
+
+	redisDB, err := strconv.Atoi(os.Getenv("REDIS_DB"))
+	if err != nil {
+		redisDB = 0 // default value
+	}
+
+	cacheConfig := CacheConfig{
+		Driver:        os.Getenv("CACHE_DRIVER"),
+		RedisAddr:     os.Getenv("REDIS_ADDR"),
+		RedisPassword: os.Getenv("REDIS_PASSWORD"),
+		RedisDB:       redisDB,
 	}
 
 	config := Config{
@@ -84,6 +144,7 @@ func Load() (*Config, error) {
 		Database:  databaseConfig,
 		Logger:    loggerConfig,
 		OrmConfig: ormConfig,
+		Cache:     cacheConfig,
 	}
 
 	return &config, nil
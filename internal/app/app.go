@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -31,7 +32,10 @@ func New(cfg *config.Config) (*App, error) {
 	// Initialize server
 	server, err := server.New(cfg, container)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize server: %w", err)
+		return nil, errors.Join(
+			fmt.Errorf("failed to initialize server: %w", err),
+			container.Stop(context.Background()),
+		)
 	}
 
 	return &App{
@@ -42,6 +46,11 @@ func New(cfg *config.Config) (*App, error) {
 }
 
 func (a *App) Start() error {
+	// Start the container's dependencies before serving requests
+	if err := a.container.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to start container: %w", err)
+	}
+
 	// Setup graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -73,9 +82,9 @@ func (a *App) Shutdown() error {
 		return fmt.Errorf("server shutdown failed: %w", err)
 	}
 
-	// Close container resources
-	if err := a.container.Close(); err != nil {
-		return fmt.Errorf("failed to close container: %w", err)
+	// Stop container resources
+	if err := a.container.Stop(ctx); err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,78 @@
+// Package buildinfo describes the running build and the API changes it
+// ships. Version, Commit and BuildTime are set at link time, e.g.
+//
+//	go build -ldflags "-X github.com/AyoubTahir/projects_management/internal/buildinfo.Commit=$(git rev-parse HEAD)"
+//
+// and otherwise fall back to the VCS stamp of the Go toolchain.
+package buildinfo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"runtime"
+	"runtime/debug"
+	"sort"
+
+	"github.com/AyoubTahir/projects_management/pkg/types"
+)
+
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Get returns the version, commit and build time of the running binary
+func Get() types.Version {
+	info := types.Version{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	return info
+}
+
+//go:embed changelog.json
+var changelogJSON []byte
+
+// changelog is parsed at startup, so a malformed changelog.json fails fast
+var changelog = mustParseChangelog(changelogJSON)
+
+func mustParseChangelog(data []byte) []types.ChangelogEntry {
+	var entries []types.ChangelogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		panic("buildinfo: invalid changelog.json: " + err.Error())
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Date > entries[j].Date })
+	return entries
+}
+
+// Changelog returns the API changes made on or after since, a YYYY-MM-DD
+// date, newest first. An empty since returns every change.
+func Changelog(since string) []types.ChangelogEntry {
+	entries := make([]types.ChangelogEntry, 0, len(changelog))
+	for _, entry := range changelog {
+		if entry.Date >= since {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
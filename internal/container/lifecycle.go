@@ -0,0 +1,84 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Lifecycle runs the start and stop hooks of the dependencies. Providers
+// take it as a parameter to register their hooks, so hooks are registered
+// in dependency order: Start runs them in that order and Stop in reverse,
+// stopping a dependency only after everything built on it.
+//
+// Stop hooks run for every dependency that was built, whether or not the
+// container was started, so that connections opened while resolving are
+// closed when New fails or the app never starts. They must therefore cope
+// with their start hook never having run.
+type Lifecycle struct {
+	hooks   []hook
+	started int
+	stopped bool
+}
+
+type hook struct {
+	onStart func(ctx context.Context) error
+	onStop  func(ctx context.Context) error
+}
+
+// OnStart registers fn to run when the container starts
+func (l *Lifecycle) OnStart(fn func(ctx context.Context) error) {
+	l.hooks = append(l.hooks, hook{onStart: fn})
+}
+
+// OnStop registers fn to run when the container stops
+func (l *Lifecycle) OnStop(fn func(ctx context.Context) error) {
+	l.hooks = append(l.hooks, hook{onStop: fn})
+}
+
+// Start runs the start hooks in order. When one fails, the container is
+// stopped and the error returned.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	if l.stopped {
+		return errors.New("container: already stopped")
+	}
+
+	for ; l.started < len(l.hooks); l.started++ {
+		if h := l.hooks[l.started]; h.onStart != nil {
+			if err := h.onStart(ctx); err != nil {
+				return errors.Join(err, l.Stop(ctx))
+			}
+		}
+	}
+	return nil
+}
+
+// Stop runs every stop hook in reverse order, once. Every hook runs even
+// when some fail; their errors are joined.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	if l.stopped {
+		return nil
+	}
+	l.stopped = true
+
+	var errs []error
+	for i := len(l.hooks) - 1; i >= 0; i-- {
+		if h := l.hooks[i]; h.onStop != nil {
+			if err := h.onStop(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeHook returns a stop hook closing a connection, describing failures
+// with what it closes
+func closeHook(what string, close func() error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if err := close(); err != nil {
+			return fmt.Errorf("failed to close %s: %w", what, err)
+		}
+		return nil
+	}
+}
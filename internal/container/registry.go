@@ -0,0 +1,232 @@
+package container
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Registry builds dependencies from providers: constructors whose
+// parameters are themselves resolved from the registry by type. Each type
+// has a single provider, called at most once, so every dependency is a
+// singleton. Errors are sticky: the first registration or resolution
+// error is kept and reported by Err, so wiring reads as a list of calls
+// followed by one check. A Registry is not safe for concurrent use.
+type Registry struct {
+	providers map[reflect.Type]*provider
+	err       error
+}
+
+// provider builds the value of one type
+type provider struct {
+	// constructor is invalid for supplied values
+	constructor reflect.Value
+	params      []reflect.Type
+	returnsErr  bool
+
+	built bool
+	value reflect.Value
+	err   error
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[reflect.Type]*provider)}
+}
+
+// Provide registers constructor, a function returning a T or a T and an
+// error, as the provider of T, e.g. r.Provide(repositories.NewRepository).
+// Its parameters are resolved from the registry when T is first needed.
+// Types are matched exactly, so the provider of an interface dependency
+// must return the interface type.
+func (r *Registry) Provide(constructor any) {
+	fn := reflect.ValueOf(constructor)
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		r.fail(fmt.Errorf("container: provider must be a function, got %T", constructor))
+		return
+	}
+
+	typ := fn.Type()
+	p := &provider{constructor: fn}
+	switch {
+	case typ.IsVariadic():
+		r.fail(fmt.Errorf("container: provider %s must not be variadic", typ))
+		return
+	case typ.NumOut() == 1:
+	case typ.NumOut() == 2 && typ.Out(1) == errorType:
+		p.returnsErr = true
+	default:
+		r.fail(fmt.Errorf("container: provider %s must return a value, or a value and an error", typ))
+		return
+	}
+
+	for i := 0; i < typ.NumIn(); i++ {
+		p.params = append(p.params, typ.In(i))
+	}
+	r.register(typ.Out(0), p)
+}
+
+// Supply registers value as the T of the registry, for dependencies built
+// outside of it such as the configuration
+func Supply[T any](r *Registry, value T) {
+	r.register(typeOf[T](), &provider{built: true, value: reflect.ValueOf(&value).Elem()})
+}
+
+func (r *Registry) register(typ reflect.Type, p *provider) {
+	if _, exists := r.providers[typ]; exists {
+		r.fail(fmt.Errorf("container: %s is provided twice", typ))
+		return
+	}
+	r.providers[typ] = p
+}
+
+// Resolve returns the T of the registry, building it and its dependencies
+// first if needed
+func Resolve[T any](r *Registry) (T, error) {
+	var zero T
+	if r.err != nil {
+		return zero, r.err
+	}
+
+	value, err := r.build(typeOf[T](), nil)
+	if err != nil {
+		return zero, err
+	}
+	// A nil interface value has no dynamic type, hence the comma-ok
+	resolved, _ := value.Interface().(T)
+	return resolved, nil
+}
+
+// Populate resolves the T of the registry into target, keeping the error
+// for Err when it fails. It does nothing once the registry has failed.
+func Populate[T any](r *Registry, target *T) {
+	if r.err != nil {
+		return
+	}
+
+	value, err := Resolve[T](r)
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	*target = value
+}
+
+// Err returns the first error the registry ran into
+func (r *Registry) Err() error {
+	return r.err
+}
+
+func (r *Registry) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Validate checks that the dependencies of every provider are provided and
+// that none depends on itself, without calling any constructor, so wiring
+// mistakes surface before connections are opened
+func (r *Registry) Validate() error {
+	if r.err != nil {
+		return r.err
+	}
+
+	provided := make([]reflect.Type, 0, len(r.providers))
+	for typ := range r.providers {
+		provided = append(provided, typ)
+	}
+	sort.Slice(provided, func(i, j int) bool { return provided[i].String() < provided[j].String() })
+
+	checked := make(map[reflect.Type]bool, len(provided))
+	var visit func(typ reflect.Type, path []reflect.Type) error
+	visit = func(typ reflect.Type, path []reflect.Type) error {
+		if checked[typ] {
+			return nil
+		}
+		p, ok := r.providers[typ]
+		if !ok {
+			return missingError(typ, path)
+		}
+		if err := cycleError(typ, path); err != nil {
+			return err
+		}
+
+		path = append(path, typ)
+		for _, param := range p.params {
+			if err := visit(param, path); err != nil {
+				return err
+			}
+		}
+		checked[typ] = true
+		return nil
+	}
+
+	for _, typ := range provided {
+		if err := visit(typ, nil); err != nil {
+			r.fail(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// build returns the value of typ, building its dependencies first. path is
+// the chain of types being built that led to typ.
+func (r *Registry) build(typ reflect.Type, path []reflect.Type) (reflect.Value, error) {
+	p, ok := r.providers[typ]
+	if !ok {
+		return reflect.Value{}, missingError(typ, path)
+	}
+	if p.built {
+		return p.value, p.err
+	}
+	if err := cycleError(typ, path); err != nil {
+		return reflect.Value{}, err
+	}
+
+	path = append(path, typ)
+	args := make([]reflect.Value, len(p.params))
+	for i, param := range p.params {
+		arg, err := r.build(param, path)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		args[i] = arg
+	}
+
+	out := p.constructor.Call(args)
+	p.built = true
+	p.value = out[0]
+	if p.returnsErr && !out[1].IsNil() {
+		p.err = out[1].Interface().(error)
+	}
+	return p.value, p.err
+}
+
+func missingError(typ reflect.Type, path []reflect.Type) error {
+	if len(path) == 0 {
+		return fmt.Errorf("container: no provider for %s", typ)
+	}
+	return fmt.Errorf("container: no provider for %s, needed by %s", typ, path[len(path)-1])
+}
+
+// cycleError reports a cycle when typ is already being built along path
+func cycleError(typ reflect.Type, path []reflect.Type) error {
+	for i, building := range path {
+		if building == typ {
+			names := make([]string, 0, len(path)-i+1)
+			for _, t := range path[i:] {
+				names = append(names, t.String())
+			}
+			names = append(names, typ.String())
+			return fmt.Errorf("container: dependency cycle: %s", strings.Join(names, " -> "))
+		}
+	}
+	return nil
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AyoubTahir/projects_management/pkg/orm"
+	"github.com/AyoubTahir/projects_management/pkg/query"
+	"github.com/AyoubTahir/projects_management/pkg/types"
+	"github.com/AyoubTahir/projects_management/pkg/validator"
+)
+
+type AdminHandler struct {
+	orm       *orm.Orm
+	Validator *validator.Validator
+}
+
+func NewAdminHandler(orm *orm.Orm, validatorOpts ...validator.Option) AdminHandlerI {
+	return &AdminHandler{
+		orm:       orm,
+		Validator: validator.New(validatorOpts...),
+	}
+}
+
+// slowQueriesVars are the rules of SlowQueries' query parameters
+var slowQueriesVars = map[string]string{
+	"minutes": "omitempty,int,min=1",
+	"limit":   "omitempty,int,min=1",
+}
+
+// SlowQueries returns the slowest queries of the last `minutes` minutes
+// (default 15), at most `limit` of them (default 50)
+func (h *AdminHandler) SlowQueries(w http.ResponseWriter, r *http.Request) {
+	if !validateVars(w, r, h.Validator, slowQueriesVars) {
+		return
+	}
+
+	minutes, err := query.PositiveInt(r, "minutes", 15)
+	if err != nil {
+		JsonResponse(w, errorStatus(err), types.RouteResponse{
+			Status:  false,
+			Message: "Invalid minutes",
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	limit, err := query.PositiveInt(r, "limit", 50)
+	if err != nil {
+		JsonResponse(w, errorStatus(err), types.RouteResponse{
+			Status:  false,
+			Message: "Invalid limit",
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	events := h.orm.SlowQueries(time.Duration(minutes)*time.Minute, limit)
+
+	queries := make([]types.SlowQuery, len(events))
+	for i, event := range events {
+		queries[i] = types.SlowQuery{
+			Query:      event.Query,
+			Args:       event.Args,
+			StartedAt:  event.Time,
+			DurationMs: float64(event.Duration) / float64(time.Millisecond),
+			Rows:       event.Rows,
+			Caller:     event.Caller,
+			RequestID:  event.RequestID,
+			Route:      event.Route,
+		}
+		if event.Err != nil {
+			queries[i].Error = event.Err.Error()
+		}
+	}
+
+	JsonResponse(w, http.StatusOK, types.RouteResponse{
+		Status:  true,
+		Message: "Slow queries retrieved successfully",
+		Data:    queries,
+	})
+}
@@ -2,23 +2,39 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
+	"github.com/AyoubTahir/projects_management/internal/mappers"
 	"github.com/AyoubTahir/projects_management/internal/services"
+	"github.com/AyoubTahir/projects_management/pkg/apperr"
+	"github.com/AyoubTahir/projects_management/pkg/orm"
+	"github.com/AyoubTahir/projects_management/pkg/param"
+	"github.com/AyoubTahir/projects_management/pkg/reqctx"
 	"github.com/AyoubTahir/projects_management/pkg/types"
+	"github.com/AyoubTahir/projects_management/pkg/validator"
+	"github.com/gorilla/mux"
 )
 
 type Handler struct {
 	Service *services.Service
 	User    UserHandlerI
+	Admin   AdminHandlerI
+	Health  HealthHandlerI
+	Meta    MetaHandlerI
 	// Add other service dependencies as needed
 }
 
-func NewHandler(service *services.Service) *Handler {
+// NewHandler creates the handlers. validatorOpts configure the validator of
+// each handler, e.g. with the checker of the unique_db rule.
+func NewHandler(service *services.Service, orm *orm.Orm, capabilities types.Capabilities, validatorOpts ...validator.Option) *Handler {
 	return &Handler{
 		Service: service,
-		User:    NewUserHandler(service),
+		User:    NewUserHandler(service, validatorOpts...),
+		Admin:   NewAdminHandler(orm, validatorOpts...),
+		Health:  NewHealthHandler(orm),
+		Meta:    NewMetaHandler(capabilities, validatorOpts...),
 	}
 }
 
@@ -28,12 +44,109 @@ type UserHandlerI interface {
 	// Add other user-related methods as needed
 }
 
+type AdminHandlerI interface {
+	SlowQueries(w http.ResponseWriter, r *http.Request)
+}
+
+type HealthHandlerI interface {
+	Health(w http.ResponseWriter, r *http.Request)
+}
+
+type MetaHandlerI interface {
+	Locales(w http.ResponseWriter, r *http.Request)
+	Version(w http.ResponseWriter, r *http.Request)
+	Changelog(w http.ResponseWriter, r *http.Request)
+	Capabilities(w http.ResponseWriter, r *http.Request)
+}
+
+// JsonResponse writes response as JSON. Sensitive columns are stripped from
+// raw rows in Data, so they never leak through a handler's Select list, and
+// field names follow the mappers naming policy.
 func JsonResponse(w http.ResponseWriter, status int, response types.RouteResponse) {
+	response.Data = mappers.ApplyNaming(mappers.Sanitize(response.Data))
+	response.Errors = mappers.ApplyNaming(response.Errors)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(response)
 }
 
+// errorStatus returns the status code for a failed call: 400 for malformed
+// request parameters, 409 and 422 for conflicting or unprocessable data,
+// 503 when the database has no connection available in time, 500 otherwise
+func errorStatus(err error) int {
+	var paramErr *param.Error
+	if errors.As(err, &paramErr) {
+		return http.StatusBadRequest
+	}
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) {
+		switch appErr.Kind {
+		case apperr.Conflict:
+			return http.StatusConflict
+		case apperr.Unprocessable:
+			return http.StatusUnprocessableEntity
+		case apperr.NotFound:
+			return http.StatusNotFound
+		}
+	}
+	if errors.Is(err, orm.ErrPoolExhausted) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// errorMessage returns the client-safe message of an application error,
+// or fallback for any other error
+func errorMessage(err error, fallback string) string {
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) {
+		return appErr.Message
+	}
+	return fallback
+}
+
+// validationFailed answers a failed validation with 422 and the failed
+// rules grouped by field, in the language the client accepts. When a rule
+// could not be checked, such as unique_db without a database, it answers
+// with the status of that error instead.
+func validationFailed(w http.ResponseWriter, r *http.Request, err error) {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		JsonResponse(w, errorStatus(err), types.RouteResponse{
+			Status:  false,
+			Message: "Something went wrong",
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	JsonResponse(w, http.StatusUnprocessableEntity, types.RouteResponse{
+		Status:  false,
+		Message: "Validation error",
+		Errors:  verrs.Localize(reqctx.Locale(r.Context())).ErrorsMap(),
+	})
+}
+
+// validateVars validates the path and query variables of r against rules,
+// in the rule language of request bodies, answering 422 when they are
+// invalid. Path variables win over query parameters of the same name.
+func validateVars(w http.ResponseWriter, r *http.Request, v *validator.Validator, rules map[string]string) bool {
+	vars := make(map[string]string)
+	for name, values := range r.URL.Query() {
+		vars[name] = values[0]
+	}
+	for name, value := range mux.Vars(r) {
+		vars[name] = value
+	}
+
+	if _, err := v.ValidateVars(vars, rules); err != nil {
+		validationFailed(w, r, err)
+		return false
+	}
+	return true
+}
+
 func ParseJSON(r *http.Request, v any) error {
 	if r.Body == nil {
 		return fmt.Errorf("missing request body")
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AyoubTahir/projects_management/pkg/orm"
+	"github.com/AyoubTahir/projects_management/pkg/types"
+)
+
+type HealthHandler struct {
+	orm *orm.Orm
+}
+
+func NewHealthHandler(orm *orm.Orm) HealthHandlerI {
+	return &HealthHandler{orm: orm}
+}
+
+// Health reports the database pools and the query cache, answering 503
+// while the primary is unreachable. A failing cache only degrades the
+// service, since queries bypass it.
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	stats := h.orm.Stats()
+
+	health := types.Health{
+		Database: types.DatabaseHealth{Primary: poolHealth(stats.Primary), Hedges: stats.Hedges},
+	}
+	for _, replica := range stats.Replicas {
+		health.Database.Replicas = append(health.Database.Replicas, poolHealth(replica))
+	}
+	if cache := h.orm.CacheStats(); cache != nil {
+		health.Cache = &types.CacheHealth{Healthy: cache.Healthy, Errors: cache.Errors}
+		if !cache.Healthy {
+			health.Degraded = append(health.Degraded, "cache")
+		}
+	}
+
+	if !stats.Primary.Healthy {
+		JsonResponse(w, http.StatusServiceUnavailable, types.RouteResponse{
+			Status:  false,
+			Message: "Database unavailable",
+			Data:    health,
+		})
+		return
+	}
+
+	message := "Service healthy"
+	if len(health.Degraded) > 0 {
+		message = "Service degraded"
+	}
+	JsonResponse(w, http.StatusOK, types.RouteResponse{
+		Status:  true,
+		Message: message,
+		Data:    health,
+	})
+}
+
+func poolHealth(stats orm.PoolStats) types.PoolHealth {
+	return types.PoolHealth{
+		Healthy:            stats.Healthy,
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     float64(stats.WaitDuration) / float64(time.Millisecond),
+		Saturation:         stats.Saturation,
+		AcquireWaitCount:   stats.Acquire.WaitCount,
+		AcquireWaitMs:      float64(stats.Acquire.WaitDuration) / float64(time.Millisecond),
+		AcquireTimeouts:    stats.Acquire.Timeouts,
+		StmtCache:          stats.StmtCache,
+	}
+}
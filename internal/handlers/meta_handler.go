@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AyoubTahir/projects_management/internal/buildinfo"
+	"github.com/AyoubTahir/projects_management/pkg/types"
+	"github.com/AyoubTahir/projects_management/pkg/validator"
+)
+
+// timezones are the zones offered to users. Any IANA zone is accepted on
+// input; this is the list clients show in pickers.
+var timezones = []string{
+	"UTC",
+	"Africa/Cairo",
+	"Africa/Casablanca",
+	"Africa/Johannesburg",
+	"Africa/Lagos",
+	"America/Anchorage",
+	"America/Bogota",
+	"America/Chicago",
+	"America/Denver",
+	"America/Halifax",
+	"America/Los_Angeles",
+	"America/Mexico_City",
+	"America/New_York",
+	"America/Sao_Paulo",
+	"America/Toronto",
+	"Asia/Dubai",
+	"Asia/Hong_Kong",
+	"Asia/Jakarta",
+	"Asia/Kolkata",
+	"Asia/Seoul",
+	"Asia/Shanghai",
+	"Asia/Singapore",
+	"Asia/Tokyo",
+	"Atlantic/Reykjavik",
+	"Australia/Perth",
+	"Australia/Sydney",
+	"Europe/Amsterdam",
+	"Europe/Berlin",
+	"Europe/Istanbul",
+	"Europe/London",
+	"Europe/Madrid",
+	"Europe/Moscow",
+	"Europe/Paris",
+	"Pacific/Auckland",
+	"Pacific/Honolulu",
+}
+
+type MetaHandler struct {
+	Validator    *validator.Validator
+	capabilities types.Capabilities
+}
+
+func NewMetaHandler(capabilities types.Capabilities, validatorOpts ...validator.Option) MetaHandlerI {
+	return &MetaHandler{
+		Validator:    validator.New(validatorOpts...),
+		capabilities: capabilities,
+	}
+}
+
+// Locales lists the locales validation messages are available in and the
+// timezones users can pick
+func (h *MetaHandler) Locales(w http.ResponseWriter, r *http.Request) {
+	JsonResponse(w, http.StatusOK, types.RouteResponse{
+		Status:  true,
+		Message: "Supported locales and timezones",
+		Data: types.Locales{
+			Locales:   validator.DefaultCatalog.Locales(),
+			Timezones: timezones,
+		},
+	})
+}
+
+// Version returns the version, commit and build time of the server
+func (h *MetaHandler) Version(w http.ResponseWriter, r *http.Request) {
+	JsonResponse(w, http.StatusOK, types.RouteResponse{
+		Status:  true,
+		Message: "Server version",
+		Data:    buildinfo.Get(),
+	})
+}
+
+// changelogVars are the rules of Changelog's query parameters
+var changelogVars = map[string]string{
+	"since": "omitempty,datetime=2006-01-02",
+}
+
+// Changelog lists the API changes, newest first, only those made on or
+// after the `since` date when given
+func (h *MetaHandler) Changelog(w http.ResponseWriter, r *http.Request) {
+	if !validateVars(w, r, h.Validator, changelogVars) {
+		return
+	}
+
+	JsonResponse(w, http.StatusOK, types.RouteResponse{
+		Status:  true,
+		Message: "API changelog",
+		Data:    buildinfo.Changelog(r.URL.Query().Get("since")),
+	})
+}
+
+// Capabilities describes the optional features the server runs with, the
+// locales it speaks and its limits
+func (h *MetaHandler) Capabilities(w http.ResponseWriter, r *http.Request) {
+	capabilities := h.capabilities
+	capabilities.Locales = validator.DefaultCatalog.Locales()
+
+	JsonResponse(w, http.StatusOK, types.RouteResponse{
+		Status:  true,
+		Message: "Server capabilities",
+		Data:    capabilities,
+	})
+}
@@ -2,12 +2,12 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
 
+	"github.com/AyoubTahir/projects_management/internal/mappers"
 	"github.com/AyoubTahir/projects_management/internal/services"
+	"github.com/AyoubTahir/projects_management/pkg/param"
 	"github.com/AyoubTahir/projects_management/pkg/types"
 	"github.com/AyoubTahir/projects_management/pkg/validator"
-	"github.com/gorilla/mux"
 )
 
 type UserHandler struct {
@@ -15,10 +15,10 @@ type UserHandler struct {
 	Validator *validator.Validator
 }
 
-func NewUserHandler(service *services.Service) UserHandlerI {
+func NewUserHandler(service *services.Service, validatorOpts ...validator.Option) UserHandlerI {
 	return &UserHandler{
 		service:   service,
-		Validator: validator.New(),
+		Validator: validator.New(validatorOpts...),
 	}
 }
 
@@ -34,21 +34,17 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.Validator.Validate(user); err != nil {
-		JsonResponse(w, http.StatusUnprocessableEntity, types.RouteResponse{
-			Status:  false,
-			Message: "Validation error",
-			Errors:  h.Validator.GetErrors(),
-		})
+	if err := h.Validator.ValidateContext(r.Context(), &user); err != nil {
+		validationFailed(w, r, err)
 		return
 	}
 
 	data, err := h.service.User.CreateUser(r.Context(), &user)
 	if err != nil {
 		//http.Error(w, err.Error(), http.StatusInternalServerError)
-		JsonResponse(w, http.StatusInternalServerError, types.RouteResponse{
+		JsonResponse(w, errorStatus(err), types.RouteResponse{
 			Status:  false,
-			Message: "Something went wrong",
+			Message: errorMessage(err, "Something went wrong"),
 			Errors:  err.Error(),
 		})
 		return
@@ -57,15 +53,14 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	JsonResponse(w, http.StatusCreated, types.RouteResponse{
 		Status:  true,
 		Message: "User created successfully",
-		Data:    data,
+		Data:    mappers.User(data),
 	})
 }
 
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	id, err := param.Parse(r, "id", types.ParseUserID)
 	if err != nil {
-		JsonResponse(w, http.StatusBadRequest, types.RouteResponse{
+		JsonResponse(w, errorStatus(err), types.RouteResponse{
 			Status:  false,
 			Message: "Invalid user ID",
 			Errors:  err.Error(),
@@ -75,7 +70,7 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.service.User.GetUserByID(r.Context(), id)
 	if err != nil {
-		JsonResponse(w, http.StatusInternalServerError, types.RouteResponse{
+		JsonResponse(w, errorStatus(err), types.RouteResponse{
 			Status:  false,
 			Message: "Failed to get user",
 			Errors:  err.Error(),
@@ -86,7 +81,6 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	JsonResponse(w, http.StatusOK, types.RouteResponse{
 		Status:  true,
 		Message: "User retrieved successfully",
-		//add a fake map to data
-		Data: user,
+		Data:    mappers.User(user),
 	})
 }
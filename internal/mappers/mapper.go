@@ -0,0 +1,53 @@
+// Package mappers converts repository rows into the response structs the
+// API returns, so handlers never expose raw columns such as passwords.
+package mappers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/AyoubTahir/projects_management/pkg/types"
+)
+
+// TimeFormat is the format of every timestamp in API responses
+const TimeFormat = time.RFC3339
+
+func int64Value(row map[string]interface{}, column string) int64 {
+	switch v := row[column].(type) {
+	case int64:
+		return v
+	case types.UserID:
+		return int64(v)
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	case []byte:
+		n, _ := strconv.ParseInt(string(v), 10, 64)
+		return n
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	}
+	return 0
+}
+
+func stringValue(row map[string]interface{}, column string) string {
+	switch v := row[column].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+// timeValue formats a timestamp column in UTC, or returns "" when the
+// column is missing or null
+func timeValue(row map[string]interface{}, column string) string {
+	t, ok := row[column].(time.Time)
+	if !ok || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(TimeFormat)
+}
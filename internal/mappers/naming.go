@@ -0,0 +1,149 @@
+package mappers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// NamingPolicy is the case of the field names in API responses
+type NamingPolicy int32
+
+const (
+	CamelCase NamingPolicy = iota
+	SnakeCase
+)
+
+// ParseNamingPolicy parses "camel" or "snake", defaulting to CamelCase
+// when name is empty
+func ParseNamingPolicy(name string) (NamingPolicy, error) {
+	switch strings.ToLower(name) {
+	case "", "camel", "camelcase":
+		return CamelCase, nil
+	case "snake", "snake_case":
+		return SnakeCase, nil
+	}
+	return CamelCase, fmt.Errorf("unknown JSON naming policy: %s", name)
+}
+
+var namingPolicy atomic.Int32
+
+// SetNamingPolicy sets the field naming of every response
+func SetNamingPolicy(policy NamingPolicy) {
+	namingPolicy.Store(int32(policy))
+}
+
+// Naming returns the current naming policy
+func Naming() NamingPolicy {
+	return NamingPolicy(namingPolicy.Load())
+}
+
+// ApplyNaming renames the keys of data to the naming policy. Raw rows are
+// renamed from their column names; response structs, whose json tags are
+// camelCase, are only re-keyed under SnakeCase.
+func ApplyNaming(data interface{}) interface{} {
+	policy := Naming()
+
+	switch data.(type) {
+	case nil, string:
+		return data
+	case map[string]interface{}, map[string][]string, []map[string]interface{}, []interface{}:
+		return rename(data, policy)
+	}
+
+	if policy == CamelCase {
+		return data
+	}
+
+	// Re-key structs through their JSON form
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return data
+	}
+	var generic interface{}
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return data
+	}
+	return rename(generic, policy)
+}
+
+func rename(data interface{}, policy NamingPolicy) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		renamed := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			renamed[convertName(key, policy)] = rename(value, policy)
+		}
+		return renamed
+	case map[string][]string:
+		renamed := make(map[string][]string, len(v))
+		for key, value := range v {
+			renamed[convertName(key, policy)] = value
+		}
+		return renamed
+	case []map[string]interface{}:
+		renamed := make([]map[string]interface{}, len(v))
+		for i, row := range v {
+			renamed[i] = rename(row, policy).(map[string]interface{})
+		}
+		return renamed
+	case []interface{}:
+		renamed := make([]interface{}, len(v))
+		for i, value := range v {
+			renamed[i] = rename(value, policy)
+		}
+		return renamed
+	}
+	return data
+}
+
+func convertName(name string, policy NamingPolicy) string {
+	if policy == SnakeCase {
+		return toSnake(name)
+	}
+	return toCamel(name)
+}
+
+// toCamel converts snake_case to camelCase, leaving other names as they are
+func toCamel(name string) string {
+	if !strings.Contains(name, "_") {
+		return name
+	}
+
+	var sb strings.Builder
+	upper := false
+	for i, r := range name {
+		switch {
+		case r == '_' && i > 0:
+			upper = true
+		case upper:
+			sb.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// toSnake converts camelCase to snake_case, keeping acronyms together, so
+// "userID" becomes "user_id" and "HTTPServer" "http_server"
+func toSnake(name string) string {
+	runes := []rune(name)
+
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			acronymEnd := i > 0 && unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || acronymEnd {
+				sb.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,68 @@
+package mappers
+
+import (
+	"strings"
+	"sync"
+)
+
+// sensitiveColumns are never serialized in responses, whatever the handler
+// selected. Struct responses hide such fields with a `json:"-"` tag.
+var sensitiveColumns = struct {
+	sync.RWMutex
+	names map[string]bool
+}{names: map[string]bool{
+	"password":       true,
+	"password_hash":  true,
+	"token":          true,
+	"token_hash":     true,
+	"remember_token": true,
+	"api_key":        true,
+	"secret":         true,
+}}
+
+// RegisterSensitive adds columns that must never be serialized in responses
+func RegisterSensitive(columns ...string) {
+	sensitiveColumns.Lock()
+	defer sensitiveColumns.Unlock()
+
+	for _, column := range columns {
+		sensitiveColumns.names[strings.ToLower(column)] = true
+	}
+}
+
+func isSensitive(column string) bool {
+	sensitiveColumns.RLock()
+	defer sensitiveColumns.RUnlock()
+
+	return sensitiveColumns.names[strings.ToLower(column)]
+}
+
+// Sanitize removes the sensitive columns from raw rows in data, including
+// rows nested in maps and slices. Rows are copied, so the caller's data is
+// left untouched. Other values are returned as is.
+func Sanitize(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		clean := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if isSensitive(key) {
+				continue
+			}
+			clean[key] = Sanitize(value)
+		}
+		return clean
+	case []map[string]interface{}:
+		clean := make([]map[string]interface{}, len(v))
+		for i, row := range v {
+			clean[i] = Sanitize(row).(map[string]interface{})
+		}
+		return clean
+	case []interface{}:
+		clean := make([]interface{}, len(v))
+		for i, value := range v {
+			clean[i] = Sanitize(value)
+		}
+		return clean
+	}
+	return data
+}
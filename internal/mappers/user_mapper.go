@@ -0,0 +1,16 @@
+package mappers
+
+import "github.com/AyoubTahir/projects_management/pkg/types"
+
+// User maps a users row to its response, leaving out the password
+func User(row map[string]interface{}) types.UserResponse {
+	return types.UserResponse{
+		ID:        types.UserID(int64Value(row, "id")),
+		UserName:  stringValue(row, "username"),
+		Email:     stringValue(row, "email"),
+		Timezone:  stringValue(row, "timezone"),
+		Locale:    stringValue(row, "locale"),
+		CreatedAt: timeValue(row, "created_at"),
+		UpdatedAt: timeValue(row, "updated_at"),
+	}
+}
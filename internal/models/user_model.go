@@ -1,11 +1,17 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/AyoubTahir/projects_management/pkg/types"
+)
 
 type User struct {
-	ID        int64     `json:"id" db:"id"`
-	Email     string    `json:"email" db:"email"`
-	Username  string    `json:"username" db:"username"`
-	Password  string    `json:"password" db:"password"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID        types.UserID `json:"id" db:"id"`
+	Email     string       `json:"email" db:"email"`
+	Username  string       `json:"username" db:"username"`
+	Password  string       `json:"-" db:"password"`
+	Timezone  string       `json:"timezone" db:"timezone"`
+	Locale    string       `json:"locale" db:"locale"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
 }
@@ -8,20 +8,27 @@ import (
 )
 
 type Repository struct {
-	orm  *orm.Orm
-	User UserRepositoryI
+	orm    *orm.Orm
+	User   UserRepositoryI
+	Unique UniqueRepositoryI
 }
 
 func NewRepository(orm *orm.Orm) *Repository {
 	return &Repository{
-		orm:  orm,
-		User: NewUserRepository(orm),
+		orm:    orm,
+		User:   NewUserRepository(orm),
+		Unique: NewUniqueRepository(orm),
 		// Initialize OrderRepository here when you have it
 	}
 }
 
 type UserRepositoryI interface {
 	Create(ctx context.Context, user *types.CreateUserPayload) (map[string]interface{}, error)
-	GetByID(ctx context.Context, id int64) (map[string]interface{}, error)
+	GetByID(ctx context.Context, id types.UserID) (map[string]interface{}, error)
 	// Add other user-related methods as needed
 }
+
+// UniqueRepositoryI backs the unique_db validation rule
+type UniqueRepositoryI interface {
+	Unique(ctx context.Context, table, column string, value interface{}) (bool, error)
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/AyoubTahir/projects_management/pkg/orm"
+)
+
+type UniqueRepository struct {
+	orm *orm.Orm
+}
+
+func NewUniqueRepository(orm *orm.Orm) UniqueRepositoryI {
+	return &UniqueRepository{orm: orm}
+}
+
+// Unique reports whether no row of table has value in column. It reads
+// from the primary, as a replica may not have the latest rows yet.
+func (r *UniqueRepository) Unique(ctx context.Context, table, column string, value interface{}) (bool, error) {
+	exists, err := r.orm.Table(table).WithContext(ctx).UseWrites().Where(column, "=", value).Exists()
+	if err != nil {
+		return false, err
+	}
+	return !exists, nil
+}
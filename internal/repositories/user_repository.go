@@ -2,10 +2,10 @@ package repositories
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 
+	"github.com/AyoubTahir/projects_management/pkg/apperr"
 	"github.com/AyoubTahir/projects_management/pkg/orm"
 	"github.com/AyoubTahir/projects_management/pkg/types"
 )
@@ -20,31 +20,41 @@ func NewUserRepository(orm *orm.Orm) UserRepositoryI {
 
 func (r *UserRepository) Create(ctx context.Context, user *types.CreateUserPayload) (map[string]interface{}, error) {
 	//query := `INSERT INTO users (username, email, password, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`
-	data, err := r.orm.Table("users").Create(map[string]interface{}{
+	timezone, locale := user.Timezone, user.Locale
+	if timezone == "" {
+		timezone = types.DefaultTimezone
+	}
+	if locale == "" {
+		locale = types.DefaultLocale
+	}
+
+	data, err := r.orm.Table("users").WithContext(ctx).Create(map[string]interface{}{
 		"username": user.UserName,
 		"email":    user.Email,
 		"password": user.Password,
+		"timezone": timezone,
+		"locale":   locale,
 	})
 	//err := r.db.QueryRowContext(ctx, query, user.UserName, user.Email, user.Password, time.Now(), time.Now()).Scan(&user.UserName)
 	if err != nil {
-		return nil, fmt.Errorf("error creating user: %w", err)
+		return nil, fmt.Errorf("error creating user: %w", apperr.FromPostgres(err))
 	}
 	return data, nil
 }
 
-func (r *UserRepository) GetByID(ctx context.Context, id int64) (map[string]interface{}, error) {
+func (r *UserRepository) GetByID(ctx context.Context, id types.UserID) (map[string]interface{}, error) {
 	//query := `SELECT id, name, email, password FROM users WHERE id = $1`
 	//user := &models.User{}
 	//err := r.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Username, &user.Email, &user.Password)
 	data, err := r.orm.Table("users").
 		WithContext(ctx).
-		Select("id", "username", "email", "password").
+		Select("id", "username", "email", "timezone", "locale", "created_at", "updated_at").
 		Where("id", "=", id).
 		First()
 
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user not found: %w", err)
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil, &apperr.Error{Kind: apperr.NotFound, Message: "user not found", Err: err}
 		}
 		return nil, fmt.Errorf("error getting user: %w", err)
 	}
@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/AyoubTahir/projects_management/internal/handlers"
+	"github.com/AyoubTahir/projects_management/pkg/types"
+	"github.com/gorilla/mux"
+)
+
+const adminTokenHeader = "X-Admin-Token"
+
+// RegisterAdminRoutes registers the operational endpoints under /admin.
+// They require the X-Admin-Token header to match token, and are not
+// registered at all when no token is configured.
+func RegisterAdminRoutes(r *mux.Router, handler *handlers.Handler, token string) {
+	if token == "" {
+		return
+	}
+
+	admin := r.PathPrefix("/admin").Subrouter()
+	admin.Use(requireAdminToken(token))
+	admin.HandleFunc("/slow-queries", handler.Admin.SlowQueries).Methods("GET")
+}
+
+func requireAdminToken(token string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get(adminTokenHeader)), []byte(token)) != 1 {
+				handlers.JsonResponse(w, http.StatusUnauthorized, types.RouteResponse{
+					Status:  false,
+					Message: "Unauthorized",
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
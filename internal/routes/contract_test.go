@@ -0,0 +1,316 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AyoubTahir/projects_management/internal/handlers"
+	"github.com/AyoubTahir/projects_management/internal/services"
+	"github.com/AyoubTahir/projects_management/pkg/apperr"
+	"github.com/AyoubTahir/projects_management/pkg/orm"
+	"github.com/AyoubTahir/projects_management/pkg/types"
+	"github.com/AyoubTahir/projects_management/pkg/validator"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Run `go test ./internal/routes -run TestContract -update` to accept
+// intended response changes
+var update = flag.Bool("update", false, "rewrite the contract snapshots")
+
+const contractDir = "testdata/contract"
+
+// maskedFields change between runs and are replaced in snapshots
+var maskedFields = map[string]bool{
+	"createdAt": true,
+	"updatedAt": true,
+	"goVersion": true,
+}
+
+// contractHeaders are the response headers that are part of the contract
+var contractHeaders = []string{"Content-Type", "Allow", "Access-Control-Allow-Methods"}
+
+type contractCase struct {
+	Name    string            `json:"name"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+type contractSnapshot struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+}
+
+func TestContract(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join(contractDir, "catalog.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var catalog []contractCase
+	if err := json.Unmarshal(raw, &catalog); err != nil {
+		t.Fatalf("invalid catalog: %v", err)
+	}
+
+	server := httptest.NewServer(newContractRouter(newContractHandler()))
+	defer server.Close()
+
+	for _, tc := range catalog {
+		t.Run(tc.Name, func(t *testing.T) {
+			got := replay(t, server, tc)
+			path := filepath.Join(contractDir, "snapshots", tc.Name+".json")
+
+			if *update {
+				if err := os.WriteFile(path, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("missing snapshot, run with -update to create it: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("response contract changed for %s %s\n--- want\n%s\n--- got\n%s", tc.Method, tc.Path, want, got)
+			}
+		})
+	}
+}
+
+// replay sends the catalog request and returns its masked snapshot
+func replay(t *testing.T, server *httptest.Server, tc contractCase) []byte {
+	t.Helper()
+
+	var body io.Reader
+	if len(tc.Body) > 0 {
+		body = bytes.NewReader(tc.Body)
+	}
+
+	req, err := http.NewRequest(tc.Method, server.URL+tc.Path, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range tc.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	snapshot := contractSnapshot{Status: resp.StatusCode, Headers: map[string]string{}}
+	for _, name := range contractHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			snapshot.Headers[name] = value
+		}
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, &snapshot.Body); err != nil {
+			t.Fatalf("response is not JSON: %v\n%s", err, respBody)
+		}
+		snapshot.Body = mask(snapshot.Body)
+	}
+
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	return encoded.Bytes()
+}
+
+// mask replaces the values of maskedFields at any depth
+func mask(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if maskedFields[key] {
+				v[key] = "<masked>"
+			} else {
+				v[key] = mask(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = mask(item)
+		}
+	}
+	return value
+}
+
+// TestRegisteredMessagesKeepLocale checks that messages registered on the
+// validator are not replaced by catalog translations, whatever the client
+// accepts
+func TestRegisteredMessagesKeepLocale(t *testing.T) {
+	handler := newContractHandler()
+	v := handler.User.(*handlers.UserHandler).Validator
+	v.RegisterMessage("required", "{field} please")
+	v.RegisterFieldMessage("email", "email", "{field} looks wrong")
+
+	server := httptest.NewServer(newContractRouter(handler))
+	defer server.Close()
+
+	want := map[string][]string{
+		"userName": {"userName please"},
+		"email":    {"email looks wrong"},
+	}
+	for _, acceptLanguage := range []string{"en", "en-US,en;q=0.9", "fr"} {
+		got := replay(t, server, contractCase{
+			Method:  "POST",
+			Path:    "/users",
+			Headers: map[string]string{"Accept-Language": acceptLanguage},
+			Body:    json.RawMessage(`{"userName": "", "email": "not-an-email", "password": "S3cret-password"}`),
+		})
+
+		var snapshot struct {
+			Body struct {
+				Errors map[string][]string `json:"errors"`
+			} `json:"body"`
+		}
+		if err := json.Unmarshal(got, &snapshot); err != nil {
+			t.Fatal(err)
+		}
+		for field, messages := range want {
+			if fmt.Sprint(snapshot.Body.Errors[field]) != fmt.Sprint(messages) {
+				t.Errorf("Accept-Language %q: %s errors = %q, want %q", acceptLanguage, field, snapshot.Body.Errors[field], messages)
+			}
+		}
+	}
+}
+
+// TestRequestIDHeader checks that client request IDs are echoed only when
+// they are safe to log, and replaced otherwise
+func TestRequestIDHeader(t *testing.T) {
+	server := httptest.NewServer(newContractRouter(newContractHandler()))
+	defer server.Close()
+
+	cases := map[string]bool{
+		"req-42_a.b":             true,
+		"":                       false,
+		"id\tforged log line":    false,
+		"<script>":               false,
+		strings.Repeat("a", 128): true,
+		strings.Repeat("a", 129): false,
+	}
+	for sent, kept := range cases {
+		req, err := http.NewRequest("GET", server.URL+"/meta/version", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(requestIDHeader, sent)
+
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		got := resp.Header.Get(requestIDHeader)
+		if kept && got != sent {
+			t.Errorf("request ID %q echoed as %q", sent, got)
+		}
+		if !kept && (got == sent || !validRequestID(got)) {
+			t.Errorf("request ID %q replaced by %q, want a generated ID", sent, got)
+		}
+	}
+}
+
+// newContractHandler builds the handlers on top of stub services so the
+// suite runs without a database
+func newContractHandler() *handlers.Handler {
+	capabilities := types.Capabilities{
+		Features: map[string]bool{"admin": false, "queryCache": false, "readReplicas": false},
+		Limits:   types.CapabilitiesLimits{PasswordMinLength: 8, RequestTimeoutSeconds: 30},
+	}
+	return handlers.NewHandler(&services.Service{User: stubUserService{}}, nil, capabilities,
+		validator.WithUniqueChecker(stubUniqueChecker{}))
+}
+
+// newContractRouter builds the API router around handler
+func newContractRouter(handler *handlers.Handler) *mux.Router {
+	r := mux.NewRouter()
+	r.Use(requestContext)
+	RegisterUserRoutes(r, handler)
+	RegisterMetaRoutes(r, handler)
+	RegisterFallbackHandlers(r, nil)
+	return r
+}
+
+// stubUniqueChecker reports registered@example.com as taken, while
+// duplicate@example.com passes the check and then conflicts on insert, as
+// when two requests race
+type stubUniqueChecker struct{}
+
+func (stubUniqueChecker) Unique(_ context.Context, table, column string, value interface{}) (bool, error) {
+	return !(table == "users" && column == "email" && value == "registered@example.com"), nil
+}
+
+type stubUserService struct{}
+
+func (stubUserService) CreateUser(_ context.Context, user *types.CreateUserPayload) (map[string]interface{}, error) {
+	if user.UserName == "taken" {
+		return nil, errors.New("failed to create user: username already taken")
+	}
+	if user.Email == "duplicate@example.com" {
+		err := apperr.FromPostgres(&pq.Error{
+			Code:       "23505",
+			Constraint: "users_email_key",
+			Detail:     "Key (email)=(duplicate@example.com) already exists.",
+		})
+		return nil, fmt.Errorf("failed to create user: error creating user: %w", err)
+	}
+
+	return map[string]interface{}{
+		"id":         1,
+		"username":   user.UserName,
+		"email":      user.Email,
+		"timezone":   user.Timezone,
+		"locale":     user.Locale,
+		"created_at": time.Now(),
+		"updated_at": time.Now(),
+	}, nil
+}
+
+func (stubUserService) GetUserByID(_ context.Context, id types.UserID) (map[string]interface{}, error) {
+	if id != 1 {
+		return nil, fmt.Errorf("failed to get user by ID: %w", &apperr.Error{
+			Kind:    apperr.NotFound,
+			Message: "user not found",
+			Err:     orm.ErrNoRows,
+		})
+	}
+
+	return map[string]interface{}{
+		"id":         id,
+		"username":   "jdoe",
+		"email":      "jdoe@example.com",
+		"timezone":   "Europe/Paris",
+		"locale":     "fr",
+		"created_at": time.Now(),
+	}, nil
+}
@@ -0,0 +1,120 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/AyoubTahir/projects_management/internal/handlers"
+	"github.com/AyoubTahir/projects_management/pkg/logger"
+	"github.com/AyoubTahir/projects_management/pkg/types"
+	"github.com/gorilla/mux"
+)
+
+// RegisterFallbackHandlers replaces mux's plain-text 404/405 responses with JSON ones
+// and answers OPTIONS/HEAD for routes that don't register them explicitly
+func RegisterFallbackHandlers(r *mux.Router, log *logger.Logger) {
+	r.NotFoundHandler = notFoundHandler(log)
+	r.MethodNotAllowedHandler = methodNotAllowedHandler(r)
+}
+
+// notFoundHandler logs every unknown path so API misuse can be spotted in the logs
+func notFoundHandler(log *logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if log != nil {
+			log.Info("unknown route: %s %s from %s (user-agent: %q)", r.Method, r.URL.Path, r.RemoteAddr, r.UserAgent())
+		}
+
+		handlers.JsonResponse(w, http.StatusNotFound, types.RouteResponse{
+			Status:  false,
+			Message: "Resource not found",
+		})
+	}
+}
+
+// methodNotAllowedHandler also answers OPTIONS for every route and HEAD for GET
+// routes, since mux only matches the methods a route was registered with
+func methodNotAllowedHandler(router *mux.Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		methods := allowedMethods(router, r)
+		if len(methods) > 0 {
+			w.Header().Set("Allow", strings.Join(methods, ", "))
+		}
+
+		switch {
+		case r.Method == http.MethodOptions:
+			if r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case r.Method == http.MethodHead && containsMethod(methods, http.MethodGet):
+			get := r.Clone(r.Context())
+			get.Method = http.MethodGet
+			router.ServeHTTP(headResponseWriter{w}, get)
+			return
+		}
+
+		handlers.JsonResponse(w, http.StatusMethodNotAllowed, types.RouteResponse{
+			Status:  false,
+			Message: "Method not allowed",
+			Errors:  map[string]interface{}{"allowedMethods": methods},
+		})
+	}
+}
+
+// allowedMethods collects the methods of every route whose path matches the request
+func allowedMethods(router *mux.Router, r *http.Request) []string {
+	var methods []string
+	seen := make(map[string]bool)
+
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		var match mux.RouteMatch
+		if !route.Match(r, &match) && match.MatchErr != mux.ErrMethodMismatch {
+			return nil
+		}
+
+		routeMethods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range routeMethods {
+			if !seen[method] {
+				seen[method] = true
+				methods = append(methods, method)
+			}
+		}
+		return nil
+	})
+
+	if len(methods) == 0 {
+		return methods
+	}
+
+	if containsMethod(methods, http.MethodGet) && !seen[http.MethodHead] {
+		methods = append(methods, http.MethodHead)
+	}
+	if !seen[http.MethodOptions] {
+		methods = append(methods, http.MethodOptions)
+	}
+
+	return methods
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// headResponseWriter keeps the headers of a GET response but drops its body
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/AyoubTahir/projects_management/internal/handlers"
+	"github.com/gorilla/mux"
+)
+
+// RegisterMetaRoutes registers the endpoints describing what the server
+// supports
+func RegisterMetaRoutes(r *mux.Router, handler *handlers.Handler) {
+	r.HandleFunc("/meta/locales", handler.Meta.Locales).Methods("GET")
+	r.HandleFunc("/meta/version", handler.Meta.Version).Methods("GET")
+	r.HandleFunc("/meta/changelog", handler.Meta.Changelog).Methods("GET")
+	r.HandleFunc("/meta/capabilities", handler.Meta.Capabilities).Methods("GET")
+}
@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/AyoubTahir/projects_management/pkg/reqctx"
+	"github.com/gorilla/mux"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the client request IDs that are kept
+const maxRequestIDLength = 128
+
+// requestContext tags the request context with its request ID and route
+// template so the queries it runs can be correlated in the logs, and with
+// the locales the client accepts. The ID is taken from the X-Request-ID
+// header when it is a valid request ID, generated otherwise, and echoed
+// back.
+func requestContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		ctx := reqctx.WithRequestID(r.Context(), requestID)
+		ctx = reqctx.WithRoute(ctx, r.Method+" "+route)
+		ctx = reqctx.WithLocale(ctx, r.Header.Get("Accept-Language"))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID reports whether a client request ID is safe to echo and
+// log: non-empty, at most maxRequestIDLength long, and made of letters,
+// digits, dots, underscores and dashes
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
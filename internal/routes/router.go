@@ -8,8 +8,15 @@ import (
 func NewRouter(container *container.Container) *mux.Router {
 	r := mux.NewRouter()
 
+	r.Use(requestContext)
+
+	r.HandleFunc("/health", container.Handler.Health.Health).Methods("GET")
 	RegisterUserRoutes(r, container.Handler)
+	RegisterMetaRoutes(r, container.Handler)
+	RegisterAdminRoutes(r, container.Handler, container.Config().Server.AdminToken)
 	// Register other routes here (e.g., order routes)
 
+	RegisterFallbackHandlers(r, container.Logger())
+
 	return r
 }
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// listenFdsStart is the first file descriptor systemd passes to a
+// socket-activated service
+const listenFdsStart = 3
+
+// listen returns the socket passed by systemd socket activation when there
+// is one, so restarts never close the listening socket. Otherwise it binds
+// addr, with SO_REUSEPORT when reusePort is set so the new process can bind
+// the port while the old one drains its connections in Shutdown.
+func listen(addr string, reusePort bool) (net.Listener, error) {
+	listener, err := activatedListener()
+	if err != nil || listener != nil {
+		return listener, err
+	}
+
+	var lc net.ListenConfig
+	if reusePort {
+		lc.Control = reusePortControl
+	}
+	return lc.Listen(context.Background(), "tcp", addr)
+}
+
+// activatedListener returns the first socket passed through LISTEN_FDS, or
+// nil when the process wasn't socket activated
+func activatedListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return nil, nil
+	}
+
+	// The variables must not leak into processes we start
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	file := os.NewFile(uintptr(listenFdsStart), "LISTEN_FD_3")
+	defer file.Close()
+
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid socket passed by systemd: %w", err)
+	}
+	return listener, nil
+}
@@ -0,0 +1,18 @@
+package server
+
+import "syscall"
+
+// soReusePort is SO_REUSEPORT from asm-generic/socket.h, which package
+// syscall doesn't define for most Linux architectures
+const soReusePort = 0xf
+
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	var sockErr error
+	err := c.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, soReusePort, 1)
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
@@ -36,8 +36,13 @@ func New(cfg *config.Config, container *container.Container) (*Server, error) {
 	}, nil
 }
 
+// Start serves on the systemd-activated socket or binds the configured port
 func (s *Server) Start() error {
-	return s.server.ListenAndServe()
+	listener, err := listen(s.server.Addr, s.cfg.Server.ReusePort)
+	if err != nil {
+		return err
+	}
+	return s.server.Serve(listener)
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
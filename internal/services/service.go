@@ -21,6 +21,6 @@ func NewService(repository *repositories.Repository) *Service {
 
 type UserServiceI interface {
 	CreateUser(ctx context.Context, user *types.CreateUserPayload) (map[string]interface{}, error)
-	GetUserByID(ctx context.Context, id int64) (map[string]interface{}, error)
+	GetUserByID(ctx context.Context, id types.UserID) (map[string]interface{}, error)
 	// Add other user-related methods as needed
 }
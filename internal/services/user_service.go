@@ -24,7 +24,7 @@ func (s *UserService) CreateUser(ctx context.Context, user *types.CreateUserPayl
 	return data, nil
 }
 
-func (s *UserService) GetUserByID(ctx context.Context, id int64) (map[string]interface{}, error) {
+func (s *UserService) GetUserByID(ctx context.Context, id types.UserID) (map[string]interface{}, error) {
 	user, err := s.repository.User.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user by ID: %w", err)
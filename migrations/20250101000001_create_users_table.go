@@ -0,0 +1,20 @@
+package migrations
+
+import "github.com/AyoubTahir/projects_management/pkg/migrations"
+
+func init() {
+	migrations.Register(20250101000001, "create_users_table",
+		func(s *migrations.Schema) {
+			s.CreateTable("users", func(t *migrations.Table) {
+				t.ID()
+				t.String("username", 255).NotNull()
+				t.String("email", 255).NotNull().Unique()
+				t.String("password", 255).NotNull()
+				t.Timestamps()
+			})
+		},
+		func(s *migrations.Schema) {
+			s.DropTable("users")
+		},
+	)
+}
@@ -0,0 +1,20 @@
+package migrations
+
+import "github.com/AyoubTahir/projects_management/pkg/migrations"
+
+func init() {
+	migrations.Register(20250101000002, "add_timezone_locale_to_users",
+		func(s *migrations.Schema) {
+			s.AlterTable("users", func(t *migrations.Table) {
+				t.String("timezone", 64).NotNull().Default("'UTC'")
+				t.String("locale", 16).NotNull().Default("'en'")
+			})
+		},
+		func(s *migrations.Schema) {
+			s.AlterTable("users", func(t *migrations.Table) {
+				t.DropColumn("timezone")
+				t.DropColumn("locale")
+			})
+		},
+	)
+}
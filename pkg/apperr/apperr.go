@@ -0,0 +1,112 @@
+// Package apperr defines application errors that carry a client-safe
+// message and a kind handlers map to a status code, and translates
+// database errors into them.
+package apperr
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Kind classifies an application error
+type Kind int
+
+const (
+	// Conflict means the request clashes with existing data, such as a
+	// duplicate unique value
+	Conflict Kind = iota + 1
+	// Unprocessable means the request is well-formed but references data
+	// that cannot be used, such as a missing foreign row
+	Unprocessable
+	// NotFound means the requested resource does not exist
+	NotFound
+)
+
+// Error is an application error. Message is safe to show to clients;
+// Err keeps the underlying error for logs.
+type Error struct {
+	Kind    Kind
+	Message string
+	// Constraint and Column name what was violated, when known
+	Constraint string
+	Column     string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Postgres error codes of the violations FromPostgres translates
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// keyDetail matches the column of a violation detail such as
+// `Key (email)=(jdoe@example.com) already exists.`
+var keyDetail = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// FromPostgres translates unique violations to Conflict errors and foreign
+// key violations to Unprocessable errors naming the offending column, e.g.
+// "email already taken". Other errors are returned unchanged.
+func FromPostgres(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	column := ""
+	if match := keyDetail.FindStringSubmatch(pqErr.Detail); match != nil {
+		column = match[1]
+	}
+	subject := column
+	if subject == "" {
+		subject = "value"
+	}
+
+	switch pqErr.Code {
+	case uniqueViolation:
+		return &Error{
+			Kind:       Conflict,
+			Message:    subject + " already taken",
+			Constraint: pqErr.Constraint,
+			Column:     column,
+			Err:        err,
+		}
+	case foreignKeyViolation:
+		return &Error{
+			Kind:       Unprocessable,
+			Message:    foreignKeyMessage(subject, pqErr.Detail),
+			Constraint: pqErr.Constraint,
+			Column:     column,
+			Err:        err,
+		}
+	}
+	return err
+}
+
+// detailTable matches the other table of a foreign key violation detail
+// such as `Key (project_id)=(5) is not present in table "projects".`
+var detailTable = regexp.MustCompile(`table "([^"]+)"`)
+
+// foreignKeyMessage describes a foreign key violation: an insert or update
+// referencing a missing row, or a delete of a row still referenced
+func foreignKeyMessage(subject string, detail string) string {
+	table := "another table"
+	if match := detailTable.FindStringSubmatch(detail); match != nil {
+		table = match[1]
+	}
+
+	if strings.Contains(detail, "still referenced") {
+		return subject + " is still referenced from " + table
+	}
+	return subject + " does not exist in " + table
+}
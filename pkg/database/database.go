@@ -23,3 +23,24 @@ func NewConnection(cfg config.DatabaseConfig) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// NewReplicaConnections opens a connection to each of cfg.ReplicaHosts
+func NewReplicaConnections(cfg config.DatabaseConfig) ([]*sql.DB, error) {
+	replicas := make([]*sql.DB, 0, len(cfg.ReplicaHosts))
+
+	for _, host := range cfg.ReplicaHosts {
+		replicaCfg := cfg
+		replicaCfg.Host = host
+
+		db, err := NewConnection(replicaCfg)
+		if err != nil {
+			for _, replica := range replicas {
+				replica.Close()
+			}
+			return nil, fmt.Errorf("replica %s: %w", host, err)
+		}
+		replicas = append(replicas, db)
+	}
+
+	return replicas, nil
+}
@@ -0,0 +1,47 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrNotAcquired = errors.New("lock is held by another owner")
+	ErrNotHeld     = errors.New("lock is no longer held")
+)
+
+// Locker acquires named locks shared by every instance of the application
+type Locker interface {
+	// Acquire tries to take the lock without waiting and returns
+	// ErrNotAcquired if another owner holds it. Backends with expiring locks
+	// release it after ttl unless it is renewed.
+	Acquire(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+}
+
+// Lock is a held distributed lock
+type Lock interface {
+	Key() string
+	// Renew extends the lock for ttl, returning ErrNotHeld if it was lost
+	Renew(ctx context.Context, ttl time.Duration) error
+	// Release frees the lock
+	Release(ctx context.Context) error
+}
+
+// WithLock runs fn while holding key, so the same operation running on
+// several instances executes only once. It returns ErrNotAcquired without
+// calling fn when another instance holds the lock.
+func WithLock(ctx context.Context, locker Locker, key string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	l, err := locker.Acquire(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+
+	fnErr := fn(ctx)
+
+	if err := l.Release(context.Background()); err != nil && fnErr == nil {
+		return fmt.Errorf("failed to release lock %q: %w", key, err)
+	}
+	return fnErr
+}
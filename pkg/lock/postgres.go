@@ -0,0 +1,99 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// PostgresLocker implements Locker with Postgres session-level advisory
+// locks. Each held lock pins one pooled connection until it is released;
+// if that connection dies the lock is released by the server, so ttl is
+// not needed and Renew only checks that the session is still alive.
+type PostgresLocker struct {
+	db *sql.DB
+}
+
+func NewPostgresLocker(db *sql.DB) *PostgresLocker {
+	return &PostgresLocker{db: db}
+}
+
+type postgresLock struct {
+	mu   sync.Mutex
+	key  string
+	id   int64
+	conn *sql.Conn
+}
+
+func (l *PostgresLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	id := advisoryID(key)
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", id).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire lock %q: %w", key, err)
+	}
+
+	if !acquired {
+		conn.Close()
+		return nil, ErrNotAcquired
+	}
+
+	return &postgresLock{key: key, id: id, conn: conn}, nil
+}
+
+func (l *postgresLock) Key() string {
+	return l.key
+}
+
+func (l *postgresLock) Renew(ctx context.Context, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return ErrNotHeld
+	}
+
+	if err := l.conn.PingContext(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotHeld, err)
+	}
+	return nil
+}
+
+func (l *postgresLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return ErrNotHeld
+	}
+	defer func() {
+		l.conn.Close()
+		l.conn = nil
+	}()
+
+	var released bool
+	if err := l.conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", l.id).Scan(&released); err != nil {
+		return fmt.Errorf("failed to release lock %q: %w", l.key, err)
+	}
+
+	if !released {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// advisoryID maps a lock name to the bigint key space of advisory locks
+func advisoryID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
@@ -34,6 +34,10 @@ func (l *Logger) Info(format string, v ...interface{}) {
 	l.Printf("[INFO] "+format, v...)
 }
 
+func (l *Logger) Warn(format string, v ...interface{}) {
+	l.Printf("[WARN] "+format, v...)
+}
+
 func (l *Logger) Error(format string, v ...interface{}) {
 	l.Printf("[ERROR] "+format, v...)
 }
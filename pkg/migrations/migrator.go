@@ -0,0 +1,194 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AyoubTahir/projects_management/pkg/orm"
+)
+
+// migrationsTable records the applied migration versions
+const migrationsTable = "schema_migrations"
+
+var ErrDuplicateVersion = errors.New("duplicate migration version")
+
+// Migration is a versioned schema change. Versions are ordered numerically,
+// conventionally a YYYYMMDDHHMMSS timestamp.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(s *Schema)
+	Down    func(s *Schema)
+}
+
+// Status reports whether a migration has been applied
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+var registry []Migration
+
+// Register adds a migration to the set returned by Registered. It is meant
+// to be called from the init function of each migration file.
+func Register(version int64, name string, up func(s *Schema), down func(s *Schema)) {
+	registry = append(registry, Migration{Version: version, Name: name, Up: up, Down: down})
+}
+
+// Registered returns the registered migrations ordered by version
+func Registered() []Migration {
+	migrations := append([]Migration(nil), registry...)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations
+}
+
+// Migrator applies and rolls back migrations, each in its own transaction
+type Migrator struct {
+	db         *sql.DB
+	dialect    orm.Dialect
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB, dialect orm.Dialect, migrations []Migration) (*Migrator, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateVersion, sorted[i].Version)
+		}
+	}
+
+	return &Migrator{db: db, dialect: dialect, migrations: sorted}, nil
+}
+
+// Up applies every pending migration in version order and returns the
+// migrations applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		record := fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (%s, %s, %s)",
+			m.dialect.Quote(migrationsTable), m.dialect.Placeholder(1), m.dialect.Placeholder(2), m.dialect.Placeholder(3))
+		if err := m.run(ctx, migration, migration.Up, record, migration.Version, migration.Name, time.Now()); err != nil {
+			return ran, err
+		}
+		ran = append(ran, migration)
+	}
+
+	return ran, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first, and
+// returns the migrations rolled back
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(ran) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == nil {
+			return ran, fmt.Errorf("migration %d_%s cannot be rolled back", migration.Version, migration.Name)
+		}
+
+		record := fmt.Sprintf("DELETE FROM %s WHERE version = %s",
+			m.dialect.Quote(migrationsTable), m.dialect.Placeholder(1))
+		if err := m.run(ctx, migration, migration.Down, record, migration.Version); err != nil {
+			return ran, err
+		}
+		ran = append(ran, migration)
+	}
+
+	return ran, nil
+}
+
+// Status lists every known migration with the time it was applied, if any
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = Status{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = &appliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// run executes the statements built by fn and the bookkeeping statement in
+// a single transaction
+func (m *Migrator) run(ctx context.Context, migration Migration, fn func(s *Schema), record string, args ...interface{}) error {
+	schema := newSchema(m.dialect)
+	fn(schema)
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	defer tx.Rollback()
+
+	for _, statement := range schema.Statements() {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migration %d_%s: %w\n%s", migration.Version, migration.Name, err, statement)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return fmt.Errorf("migration %d_%s: failed to record: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// applied creates the migrations table if needed and returns the applied
+// versions with the time they were applied
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP NOT NULL)",
+		m.dialect.Quote(migrationsTable))
+	if _, err := m.db.ExecContext(ctx, create); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", migrationsTable, err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf("SELECT version, applied_at FROM %s", m.dialect.Quote(migrationsTable)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", migrationsTable, err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
@@ -0,0 +1,273 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AyoubTahir/projects_management/pkg/orm"
+)
+
+// Schema collects the DDL statements of a migration
+type Schema struct {
+	dialect    orm.Dialect
+	statements []string
+}
+
+func newSchema(dialect orm.Dialect) *Schema {
+	return &Schema{dialect: dialect}
+}
+
+// Statements returns the SQL collected so far, in order
+func (s *Schema) Statements() []string {
+	return s.statements
+}
+
+// CreateTable creates a table with the columns, indexes and foreign keys
+// defined by fn
+func (s *Schema) CreateTable(name string, fn func(t *Table)) {
+	t := &Table{schema: s, name: name}
+	fn(t)
+
+	definitions := make([]string, 0, len(t.columns)+len(t.foreignKeys))
+	for _, column := range t.columns {
+		definitions = append(definitions, column.definition(s.dialect))
+	}
+	for _, fk := range t.foreignKeys {
+		definitions = append(definitions, fk.definition(s.dialect))
+	}
+
+	s.statements = append(s.statements, fmt.Sprintf("CREATE TABLE %s (\n\t%s\n)",
+		s.dialect.Quote(name), strings.Join(definitions, ",\n\t")))
+	s.statements = append(s.statements, t.deferred...)
+}
+
+// AlterTable changes an existing table. Columns and foreign keys defined by
+// fn are added to it.
+func (s *Schema) AlterTable(name string, fn func(t *Table)) {
+	t := &Table{schema: s, name: name}
+	fn(t)
+
+	for _, column := range t.columns {
+		s.statements = append(s.statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s",
+			s.dialect.Quote(name), column.definition(s.dialect)))
+	}
+	for _, fk := range t.foreignKeys {
+		s.statements = append(s.statements, fmt.Sprintf("ALTER TABLE %s ADD %s",
+			s.dialect.Quote(name), fk.definition(s.dialect)))
+	}
+	s.statements = append(s.statements, t.deferred...)
+}
+
+// DropTable drops a table if it exists
+func (s *Schema) DropTable(name string) {
+	s.statements = append(s.statements, fmt.Sprintf("DROP TABLE IF EXISTS %s", s.dialect.Quote(name)))
+}
+
+// AddIndex creates an index on columns of table, named after them
+func (s *Schema) AddIndex(table string, columns ...string) {
+	s.addIndex(table, false, columns)
+}
+
+// AddUniqueIndex creates a unique index on columns of table
+func (s *Schema) AddUniqueIndex(table string, columns ...string) {
+	s.addIndex(table, true, columns)
+}
+
+// DropIndex drops the index AddIndex created on columns of table
+func (s *Schema) DropIndex(table string, columns ...string) {
+	s.statements = append(s.statements, fmt.Sprintf("DROP INDEX IF EXISTS %s",
+		s.dialect.Quote(indexName(table, columns))))
+}
+
+// Raw adds a statement the builder doesn't cover
+func (s *Schema) Raw(statement string) {
+	s.statements = append(s.statements, statement)
+}
+
+func (s *Schema) addIndex(table string, unique bool, columns []string) {
+	kind := "INDEX"
+	if unique {
+		kind = "UNIQUE INDEX"
+	}
+
+	s.statements = append(s.statements, fmt.Sprintf("CREATE %s %s ON %s (%s)",
+		kind, s.dialect.Quote(indexName(table, columns)), s.dialect.Quote(table), quoteAll(s.dialect, columns)))
+}
+
+func indexName(table string, columns []string) string {
+	return fmt.Sprintf("idx_%s_%s", table, strings.Join(columns, "_"))
+}
+
+func quoteAll(dialect orm.Dialect, identifiers []string) string {
+	quoted := make([]string, len(identifiers))
+	for i, identifier := range identifiers {
+		quoted[i] = dialect.Quote(identifier)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Table defines the columns of a table being created or altered
+type Table struct {
+	schema      *Schema
+	name        string
+	columns     []*Column
+	foreignKeys []*ForeignKey
+	// deferred holds statements that must run after the table statement,
+	// such as indexes and column drops
+	deferred []string
+}
+
+// AddColumn adds a column of the given SQL type
+func (t *Table) AddColumn(name string, sqlType string) *Column {
+	column := &Column{name: name, sqlType: sqlType}
+	t.columns = append(t.columns, column)
+	return column
+}
+
+// ID adds an auto-incrementing BIGINT primary key named id
+func (t *Table) ID() *Column {
+	return t.AddColumn("id", "BIGSERIAL").Primary()
+}
+
+func (t *Table) String(name string, size int) *Column {
+	return t.AddColumn(name, fmt.Sprintf("VARCHAR(%d)", size))
+}
+
+func (t *Table) Text(name string) *Column {
+	return t.AddColumn(name, "TEXT")
+}
+
+func (t *Table) Integer(name string) *Column {
+	return t.AddColumn(name, "INTEGER")
+}
+
+func (t *Table) BigInteger(name string) *Column {
+	return t.AddColumn(name, "BIGINT")
+}
+
+func (t *Table) Boolean(name string) *Column {
+	return t.AddColumn(name, "BOOLEAN")
+}
+
+func (t *Table) Timestamp(name string) *Column {
+	return t.AddColumn(name, "TIMESTAMP")
+}
+
+func (t *Table) JSON(name string) *Column {
+	return t.AddColumn(name, "JSONB")
+}
+
+// Timestamps adds the created_at and updated_at columns the ORM fills in
+func (t *Table) Timestamps() {
+	t.Timestamp("created_at").NotNull().Default("CURRENT_TIMESTAMP")
+	t.Timestamp("updated_at").NotNull().Default("CURRENT_TIMESTAMP")
+}
+
+// Index creates an index on columns once the table exists
+func (t *Table) Index(columns ...string) {
+	t.deferIndex(false, columns)
+}
+
+// UniqueIndex creates a unique index on columns once the table exists
+func (t *Table) UniqueIndex(columns ...string) {
+	t.deferIndex(true, columns)
+}
+
+// DropColumn drops a column of an altered table
+func (t *Table) DropColumn(name string) {
+	t.deferred = append(t.deferred, fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s",
+		t.schema.dialect.Quote(t.name), t.schema.dialect.Quote(name)))
+}
+
+// ForeignKey references refTable.refColumn from column
+func (t *Table) ForeignKey(column string, refTable string, refColumn string) *ForeignKey {
+	fk := &ForeignKey{table: t.name, column: column, refTable: refTable, refColumn: refColumn}
+	t.foreignKeys = append(t.foreignKeys, fk)
+	return fk
+}
+
+func (t *Table) deferIndex(unique bool, columns []string) {
+	schema := &Schema{dialect: t.schema.dialect}
+	schema.addIndex(t.name, unique, columns)
+	t.deferred = append(t.deferred, schema.statements...)
+}
+
+// Column is a column definition
+type Column struct {
+	name         string
+	sqlType      string
+	primary      bool
+	notNull      bool
+	unique       bool
+	defaultValue string
+}
+
+func (c *Column) Primary() *Column {
+	c.primary = true
+	return c
+}
+
+func (c *Column) NotNull() *Column {
+	c.notNull = true
+	return c
+}
+
+func (c *Column) Unique() *Column {
+	c.unique = true
+	return c
+}
+
+// Default sets the column default. expr is written as is, so string
+// literals must be quoted, e.g. Default("'active'").
+func (c *Column) Default(expr string) *Column {
+	c.defaultValue = expr
+	return c
+}
+
+func (c *Column) definition(dialect orm.Dialect) string {
+	var sb strings.Builder
+	sb.WriteString(dialect.Quote(c.name))
+	sb.WriteString(" ")
+	sb.WriteString(c.sqlType)
+
+	if c.primary {
+		sb.WriteString(" PRIMARY KEY")
+	}
+	if c.notNull {
+		sb.WriteString(" NOT NULL")
+	}
+	if c.unique {
+		sb.WriteString(" UNIQUE")
+	}
+	if c.defaultValue != "" {
+		sb.WriteString(" DEFAULT ")
+		sb.WriteString(c.defaultValue)
+	}
+	return sb.String()
+}
+
+// ForeignKey is a foreign key constraint
+type ForeignKey struct {
+	table     string
+	column    string
+	refTable  string
+	refColumn string
+	onDelete  string
+}
+
+// OnDelete sets the referential action, e.g. "CASCADE" or "SET NULL"
+func (fk *ForeignKey) OnDelete(action string) *ForeignKey {
+	fk.onDelete = action
+	return fk
+}
+
+func (fk *ForeignKey) definition(dialect orm.Dialect) string {
+	constraint := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		dialect.Quote(fmt.Sprintf("fk_%s_%s", fk.table, fk.column)),
+		dialect.Quote(fk.column), dialect.Quote(fk.refTable), dialect.Quote(fk.refColumn))
+
+	if fk.onDelete != "" {
+		constraint += " ON DELETE " + fk.onDelete
+	}
+	return constraint
+}
@@ -0,0 +1,86 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrPoolExhausted is returned when no connection frees up within
+// Config.ConnAcquireTimeout
+var ErrPoolExhausted = errors.New("connection pool exhausted")
+
+// connLimiter bounds how long statements wait for one of the pool's
+// connections, separately from their execution timeout. Waiters are served
+// in arrival order.
+type connLimiter struct {
+	slots   chan struct{}
+	timeout time.Duration
+
+	waits    atomic.Int64
+	waitTime atomic.Int64
+	timeouts atomic.Int64
+}
+
+// newConnLimiter returns nil, disabling the limit, unless both the pool
+// size and the timeout are set
+func newConnLimiter(maxOpenConns int, timeout time.Duration) *connLimiter {
+	if maxOpenConns <= 0 || timeout <= 0 {
+		return nil
+	}
+	return &connLimiter{slots: make(chan struct{}, maxOpenConns), timeout: timeout}
+}
+
+// acquire waits for a free connection and returns the func releasing it
+func (l *connLimiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, nil
+	default:
+	}
+
+	start := time.Now()
+	timer := time.NewTimer(l.timeout)
+	defer timer.Stop()
+	defer func() {
+		l.waits.Add(1)
+		l.waitTime.Add(int64(time.Since(start)))
+	}()
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, nil
+	case <-timer.C:
+		l.timeouts.Add(1)
+		return nil, ErrPoolExhausted
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *connLimiter) release() {
+	<-l.slots
+}
+
+// AcquireStats reports how statements waited for a connection
+type AcquireStats struct {
+	WaitCount    int64
+	WaitDuration time.Duration
+	Timeouts     int64
+}
+
+func (l *connLimiter) stats() AcquireStats {
+	if l == nil {
+		return AcquireStats{}
+	}
+	return AcquireStats{
+		WaitCount:    l.waits.Load(),
+		WaitDuration: time.Duration(l.waitTime.Load()),
+		Timeouts:     l.timeouts.Load(),
+	}
+}
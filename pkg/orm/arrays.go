@@ -0,0 +1,104 @@
+package orm
+
+import (
+	"database/sql"
+	"reflect"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// WhereArrayContains adds a WHERE clause matching rows whose Postgres array
+// column contains value, or every element of value when it is a slice
+func (m *Model) WhereArrayContains(column string, value interface{}) *Model {
+	if kind := reflect.ValueOf(value).Kind(); kind != reflect.Slice && kind != reflect.Array {
+		value = []interface{}{value}
+	}
+
+	m.query.wheres = append(m.query.wheres, whereClause{
+		column:   sanitizeColumn(column),
+		operator: "@>",
+		value:    pq.Array(value),
+	})
+	return m
+}
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	bytesType = reflect.TypeOf([]byte(nil))
+)
+
+// isScalarList reports whether a slice or array only holds values a
+// Postgres array can store: strings, numbers, bools, times and byte
+// slices. The elements of interface slices are checked one by one.
+func isScalarList(value interface{}) bool {
+	val := reflect.ValueOf(value)
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return true
+		}
+		val = val.Elem()
+	}
+
+	if elem := val.Type().Elem(); elem.Kind() != reflect.Interface {
+		return isScalarType(elem)
+	}
+	for i := 0; i < val.Len(); i++ {
+		if item := val.Index(i); !item.IsNil() && !isScalarType(item.Elem().Type()) {
+			return false
+		}
+	}
+	return true
+}
+
+func isScalarType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == timeType || typ.ConvertibleTo(bytesType) {
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// encodeArray wraps a slice so lib/pq sends it as a Postgres array
+func encodeArray(value interface{}) interface{} {
+	return pq.Array(value)
+}
+
+// decodeArray converts a Postgres array column value into a Go slice based
+// on the column's element type, returning the raw value for other types
+func decodeArray(column *sql.ColumnType, value interface{}) interface{} {
+	var array sql.Scanner
+	var result func() interface{}
+
+	switch column.DatabaseTypeName() {
+	case "_TEXT", "_VARCHAR", "_BPCHAR", "_UUID":
+		a := &pq.StringArray{}
+		array, result = a, func() interface{} { return []string(*a) }
+	case "_INT2", "_INT4", "_INT8":
+		a := &pq.Int64Array{}
+		array, result = a, func() interface{} { return []int64(*a) }
+	case "_FLOAT4", "_FLOAT8", "_NUMERIC":
+		a := &pq.Float64Array{}
+		array, result = a, func() interface{} { return []float64(*a) }
+	case "_BOOL":
+		a := &pq.BoolArray{}
+		array, result = a, func() interface{} { return []bool(*a) }
+	default:
+		return value
+	}
+
+	if err := array.Scan(value); err != nil {
+		return value
+	}
+	return result()
+}
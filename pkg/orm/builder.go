@@ -0,0 +1,98 @@
+package orm
+
+import (
+	"strconv"
+	"strings"
+)
+
+// sqlBuilder writes a statement and collects its arguments in a single
+// pass. Placeholders are numbered as they are written, so subqueries are
+// written in place rather than built separately and renumbered.
+type sqlBuilder struct {
+	strings.Builder
+	dialect Dialect
+	args    []interface{}
+	// next is the number of the next placeholder
+	next int
+}
+
+func newSQLBuilder(dialect Dialect, startIndex int, sizeHint int) *sqlBuilder {
+	b := &sqlBuilder{dialect: dialect, next: startIndex}
+	b.Grow(sizeHint)
+	return b
+}
+
+// bind writes a placeholder for value and adds it to the arguments
+func (b *sqlBuilder) bind(value interface{}) {
+	b.writePlaceholder()
+	b.args = append(b.args, value)
+}
+
+// skip adds arguments whose placeholders are already part of raw SQL
+func (b *sqlBuilder) skip(args []interface{}) {
+	b.args = append(b.args, args...)
+	b.next += len(args)
+}
+
+// writePlaceholder writes the next placeholder, without allocating for the
+// built-in dialects
+func (b *sqlBuilder) writePlaceholder() {
+	switch b.dialect.(type) {
+	case postgresDialect:
+		var buf [20]byte
+		b.WriteByte('$')
+		b.Write(strconv.AppendInt(buf[:0], int64(b.next), 10))
+	case mysqlDialect, sqliteDialect:
+		b.WriteByte('?')
+	default:
+		b.WriteString(b.dialect.Placeholder(b.next))
+	}
+	b.next++
+}
+
+// writeRaw writes a raw condition, replacing its ? placeholders with the
+// dialect's, and binds args. The caller ensures there is one arg per ?.
+func (b *sqlBuilder) writeRaw(condition string, args []interface{}) {
+	for {
+		i := strings.IndexByte(condition, '?')
+		if i < 0 {
+			break
+		}
+		b.WriteString(condition[:i])
+		b.writePlaceholder()
+		condition = condition[i+1:]
+	}
+	b.WriteString(condition)
+	b.args = append(b.args, args...)
+}
+
+// writeConditions joins the AND and OR conditions
+func (b *sqlBuilder) writeConditions(wheres []whereClause, orWheres []whereClause) {
+	for i, where := range wheres {
+		if i > 0 {
+			b.WriteString(" AND ")
+		}
+		where.write(b)
+	}
+
+	for i, orWhere := range orWheres {
+		if len(wheres) > 0 || i > 0 {
+			b.WriteString(" OR ")
+		}
+		orWhere.write(b)
+	}
+}
+
+func (b *sqlBuilder) writeList(items []string) {
+	for i, item := range items {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(item)
+	}
+}
+
+func (b *sqlBuilder) writeInt(n int) {
+	var buf [20]byte
+	b.Write(strconv.AppendInt(buf[:0], int64(n), 10))
+}
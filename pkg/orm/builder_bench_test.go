@@ -0,0 +1,71 @@
+package orm
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+// benchOrm returns an ORM that builds postgres queries. Building never
+// touches the database, so the connection is never opened.
+func benchOrm(b *testing.B) *Orm {
+	db, err := sql.Open("postgres", "host=localhost dbname=bench sslmode=disable")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+	return New(db, Config{})
+}
+
+// BenchmarkBuildSelectSimple is a lookup by primary key, the most common
+// query of the user endpoints
+func BenchmarkBuildSelectSimple(b *testing.B) {
+	m := benchOrm(b).Table("users").
+		Select("id", "username", "email", "created_at").
+		Where("id", "=", 42)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m.ToSQL()
+	}
+}
+
+// BenchmarkBuildSelectList is a filtered, ordered and paginated listing
+func BenchmarkBuildSelectList(b *testing.B) {
+	m := benchOrm(b).Table("tasks").
+		Where("project_id", "=", 7).
+		Where("status", "IN", []string{"open", "in_progress", "review"}).
+		Where("due_at", "BETWEEN", []interface{}{"2025-01-01", "2025-12-31"}).
+		Where("deleted_at", "IS NULL", nil).
+		OrWhere("assignee_id", "=", 3).
+		OrderBy("due_at", "ASC").
+		OrderBy("id", "DESC").
+		Limit(50).
+		Offset(100)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m.ToSQL()
+	}
+}
+
+// BenchmarkBuildSelectSubquery nests a subquery and a grouped HAVING
+func BenchmarkBuildSelectSubquery(b *testing.B) {
+	db := benchOrm(b)
+	members := db.Table("project_members").Select("project_id").Where("user_id", "=", 3)
+	m := db.Table("projects").
+		Select("owner_id").
+		Where("id", "IN", members).
+		Where("archived", "=", false).
+		GroupBy("owner_id").
+		Having("COUNT(*) > ?", 2)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m.ToSQL()
+	}
+}
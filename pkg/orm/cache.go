@@ -0,0 +1,278 @@
+package orm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache stores query results for Model.Cache. Entries are tagged with the
+// tables they were read from so writes can invalidate them.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tables []string) error
+	InvalidateTable(ctx context.Context, table string) error
+}
+
+// cacheBypass is how long queries skip the cache after it fails, so an
+// unreachable backend costs one timeout per window instead of one per query
+const cacheBypass = 30 * time.Second
+
+// cacheHealth tracks the failures of the cache backend
+type cacheHealth struct {
+	// bypassUntil is the Unix time in nanoseconds until which Get and Set
+	// skip the cache
+	bypassUntil atomic.Int64
+	errors      atomic.Int64
+}
+
+// CacheStats reports the state of the cache backend
+type CacheStats struct {
+	// Healthy is false while queries bypass the cache after a failure
+	Healthy bool
+	// Errors counts the failed cache calls
+	Errors int64
+}
+
+func init() {
+	// Types scanned rows can hold besides the ones gob registers itself
+	gob.Register(time.Time{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// SetCache enables Model.Cache with the given backend
+func (db *Orm) SetCache(c Cache) {
+	db.cache = c
+}
+
+// InvalidateTable drops the cached results read from table. Create, Update,
+// Increment, Decrement and Delete call it for their table; use it after
+// writing to a table outside the ORM. It is tried even while the cache is
+// bypassed, since entries it misses are served stale once the cache is back.
+func (db *Orm) InvalidateTable(ctx context.Context, table string) error {
+	if db.cache == nil {
+		return nil
+	}
+	err := db.cache.InvalidateTable(ctx, table)
+	db.cacheFailed(ctx, err)
+	return err
+}
+
+// CacheStats reports the cache backend, or nil when no cache is set
+func (db *Orm) CacheStats() *CacheStats {
+	if db.cache == nil {
+		return nil
+	}
+	return &CacheStats{
+		Healthy: !db.cacheBypassed(),
+		Errors:  db.cacheHealth.errors.Load(),
+	}
+}
+
+// cacheFailed makes queries bypass the cache for a while when err is set,
+// unless the call failed because ctx was done
+func (db *Orm) cacheFailed(ctx context.Context, err error) {
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+	db.cacheHealth.errors.Add(1)
+	db.cacheHealth.bypassUntil.Store(time.Now().Add(cacheBypass).UnixNano())
+}
+
+func (db *Orm) cacheBypassed() bool {
+	return time.Now().UnixNano() < db.cacheHealth.bypassUntil.Load()
+}
+
+// useCache reports whether the model's query reads and stores cached
+// results. Dry runs skip the cache so that every query is captured.
+func (m *Model) useCache() bool {
+	return m.db.cache != nil && m.cacheTTL > 0 && !m.useWrites && m.query.lock == "" && !m.db.dryRun.Load() && !m.db.cacheBypassed()
+}
+
+// Cache serves Get, First and Pluck from the ORM cache for up to ttl. It
+// has no effect unless a cache was set with SetCache, and queries go to the
+// database for a while after the cache fails.
+func (m *Model) Cache(ttl time.Duration) *Model {
+	m.cacheTTL = ttl
+	return m
+}
+
+// cached returns the results stored for the query, if any
+func (m *Model) cached(query string, args []interface{}) ([]map[string]interface{}, bool) {
+	if !m.useCache() {
+		return nil, false
+	}
+
+	value, ok, err := m.db.cache.Get(m.ctx, cacheKey(query, args))
+	m.db.cacheFailed(m.ctx, err)
+	if err != nil || !ok {
+		return nil, false
+	}
+
+	var results []map[string]interface{}
+	if err := gob.NewDecoder(bytes.NewReader(value)).Decode(&results); err != nil {
+		return nil, false
+	}
+	return results, true
+}
+
+// storeCached caches the results of the query. Caching is best-effort, so
+// failures only cost a database round trip on the next call.
+func (m *Model) storeCached(query string, args []interface{}, results []map[string]interface{}) {
+	if !m.useCache() {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(results); err != nil {
+		return
+	}
+	m.db.cacheFailed(m.ctx, m.db.cache.Set(m.ctx, cacheKey(query, args), buf.Bytes(), m.cacheTTL, m.tables()))
+}
+
+// invalidateCache drops the cached results of the model's table after a
+// write. Entries that fail to be invalidated expire with their TTL.
+func (m *Model) invalidateCache() {
+	m.db.InvalidateTable(m.ctx, m.query.table)
+}
+
+// tables returns every table the query reads from
+func (m *Model) tables() []string {
+	tables := []string{m.query.table}
+	if m.query.from != nil {
+		tables = append(tables, m.query.from.tables()...)
+	}
+	for _, join := range m.query.joins {
+		if fields := strings.Fields(join.table); len(fields) > 0 {
+			tables = append(tables, fields[0])
+		}
+	}
+	for _, union := range m.query.unions {
+		tables = append(tables, union.model.tables()...)
+	}
+	for _, where := range append(m.query.wheres, m.query.orWheres...) {
+		if sub, ok := where.value.(*Model); ok {
+			tables = append(tables, sub.tables()...)
+		}
+	}
+	return tables
+}
+
+func cacheKey(query string, args []interface{}) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s %#v", query, args)))
+	return "orm:query:" + hex.EncodeToString(sum[:])
+}
+
+// memoryCacheSize is the number of entries a MemoryCache holds at most
+const memoryCacheSize = 10000
+
+// MemoryCache is an in-process Cache. Once it holds memoryCacheSize
+// entries, storing another drops the expired ones, then random ones if
+// none has expired.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+	// tables indexes the keys of the entries read from each table
+	tables map[string]map[string]struct{}
+}
+
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+	tables  []string
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]memoryCacheEntry),
+		tables:  make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(entry.expires) {
+		c.remove(key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (c *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration, tables []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[key]; exists {
+		c.remove(key)
+	} else if len(c.entries) >= memoryCacheSize {
+		c.evict()
+	}
+
+	c.entries[key] = memoryCacheEntry{value: value, expires: time.Now().Add(ttl), tables: tables}
+	for _, table := range tables {
+		if c.tables[table] == nil {
+			c.tables[table] = make(map[string]struct{})
+		}
+		c.tables[table][key] = struct{}{}
+	}
+	return nil
+}
+
+func (c *MemoryCache) InvalidateTable(_ context.Context, table string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.tables[table] {
+		c.remove(key)
+	}
+	return nil
+}
+
+// evict makes room for an entry by dropping the expired entries, or a
+// random one when none has expired
+func (c *MemoryCache) evict() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			c.remove(key)
+		}
+	}
+	if len(c.entries) < memoryCacheSize {
+		return
+	}
+
+	for key := range c.entries {
+		c.remove(key)
+		return
+	}
+}
+
+// remove drops an entry and its key from the index of its tables
+func (c *MemoryCache) remove(key string) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	delete(c.entries, key)
+
+	for _, table := range entry.tables {
+		delete(c.tables[table], key)
+		if len(c.tables[table]) == 0 {
+			delete(c.tables, table)
+		}
+	}
+}
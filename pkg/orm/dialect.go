@@ -0,0 +1,75 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrUnsupportedDialect = errors.New("unsupported dialect")
+
+// Dialect describes the SQL differences between database engines
+type Dialect interface {
+	// Name returns the dialect name used in Config.Dialect
+	Name() string
+	// Placeholder returns the bind parameter for the n-th (1-based) argument
+	Placeholder(n int) string
+	// Quote quotes a table or column identifier
+	Quote(identifier string) string
+	// SupportsReturning reports whether INSERT ... RETURNING is available;
+	// otherwise the inserted row is reloaded through LastInsertId
+	SupportsReturning() bool
+	// SupportsRowLocking reports whether FOR UPDATE / FOR SHARE are available
+	SupportsRowLocking() bool
+	// JSONExtract returns an expression extracting the value at path from a
+	// JSON column as text. Path keys must already be sanitized.
+	JSONExtract(column string, path []string) string
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string             { return "postgres" }
+func (postgresDialect) Placeholder(n int) string { return "$" + strconv.Itoa(n) }
+func (postgresDialect) Quote(id string) string   { return `"` + strings.ReplaceAll(id, `"`, `""`) + `"` }
+func (postgresDialect) SupportsReturning() bool  { return true }
+func (postgresDialect) SupportsRowLocking() bool { return true }
+func (postgresDialect) JSONExtract(column string, path []string) string {
+	return fmt.Sprintf("%s #>> '{%s}'", column, strings.Join(path, ","))
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string             { return "mysql" }
+func (mysqlDialect) Placeholder(int) string   { return "?" }
+func (mysqlDialect) Quote(id string) string   { return "`" + strings.ReplaceAll(id, "`", "``") + "`" }
+func (mysqlDialect) SupportsReturning() bool  { return false }
+func (mysqlDialect) SupportsRowLocking() bool { return true }
+func (mysqlDialect) JSONExtract(column string, path []string) string {
+	return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, '$.%s'))", column, strings.Join(path, "."))
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string             { return "sqlite" }
+func (sqliteDialect) Placeholder(int) string   { return "?" }
+func (sqliteDialect) Quote(id string) string   { return `"` + strings.ReplaceAll(id, `"`, `""`) + `"` }
+func (sqliteDialect) SupportsReturning() bool  { return true }
+func (sqliteDialect) SupportsRowLocking() bool { return false }
+func (sqliteDialect) JSONExtract(column string, path []string) string {
+	return fmt.Sprintf("json_extract(%s, '$.%s')", column, strings.Join(path, "."))
+}
+
+// DialectFor returns the dialect registered under name, defaulting to
+// postgres when name is empty
+func DialectFor(name string) (Dialect, error) {
+	switch strings.ToLower(name) {
+	case "", "postgres", "postgresql":
+		return postgresDialect{}, nil
+	case "mysql":
+		return mysqlDialect{}, nil
+	case "sqlite", "sqlite3":
+		return sqliteDialect{}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedDialect, name)
+}
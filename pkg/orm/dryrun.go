@@ -0,0 +1,35 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDryRun is matched by the errors returned while Config.DryRun is set
+var ErrDryRun = errors.New("dry run")
+
+// DryRunError carries the statement that would have been executed
+type DryRunError struct {
+	Query string
+	Args  []interface{}
+}
+
+func (e *DryRunError) Error() string {
+	return fmt.Sprintf("dry run: %s args=%v", e.Query, e.Args)
+}
+
+func (e *DryRunError) Is(target error) bool {
+	return target == ErrDryRun
+}
+
+// ToSQL returns the SELECT statement and arguments the model would execute
+func (m *Model) ToSQL() (string, []interface{}) {
+	return m.buildSelectQuery()
+}
+
+// SetDryRun toggles dry-run mode. While it is on no statement reaches the
+// database: terminal methods return a *DryRunError with the built query
+// instead. It is safe to call while queries run.
+func (db *Orm) SetDryRun(enabled bool) {
+	db.dryRun.Store(enabled)
+}
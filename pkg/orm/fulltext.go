@@ -0,0 +1,50 @@
+package orm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fullTextSearch is the search condition results are ranked against
+type fullTextSearch struct {
+	vector string
+	query  string
+}
+
+// WhereFullText adds a Postgres full-text search condition matching query
+// against the concatenated columns, e.g.
+// WhereFullText([]string{"title", "description"}, "release notes").
+// The query is parsed with plainto_tsquery, so operators in user input are
+// treated as plain words.
+func (m *Model) WhereFullText(columns []string, query string) *Model {
+	if len(columns) == 0 {
+		panic(ErrInvalidValue)
+	}
+
+	parts := make([]string, 0, len(columns))
+	for _, column := range sanitizeColumns(columns) {
+		if column != "" {
+			parts = append(parts, fmt.Sprintf("coalesce(%s, '')", column))
+		}
+	}
+	vector := fmt.Sprintf("to_tsvector(%s)", strings.Join(parts, " || ' ' || "))
+
+	m.query.wheres = append(m.query.wheres, whereClause{
+		column:   vector,
+		operator: "@@",
+		value:    query,
+	})
+	return m
+}
+
+// OrderByRank orders the results by their ts_rank against the last
+// WhereFullText condition, best matches first
+func (m *Model) OrderByRank() *Model {
+	for i := len(m.query.wheres) - 1; i >= 0; i-- {
+		if where := m.query.wheres[i]; where.operator == "@@" {
+			m.query.rank = &fullTextSearch{vector: where.column, query: where.value.(string)}
+			return m
+		}
+	}
+	panic(ErrInvalidValue)
+}
@@ -0,0 +1,175 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// HedgeStats reports the hedged reads sent since the ORM was created
+type HedgeStats struct {
+	// Sent counts the reads a second replica was asked for
+	Sent uint64 `json:"sent"`
+	// Won counts the hedged reads the second replica answered first
+	Won uint64 `json:"won"`
+}
+
+type hedgeStats struct {
+	sent atomic.Uint64
+	won  atomic.Uint64
+}
+
+func (s *hedgeStats) stats() HedgeStats {
+	return HedgeStats{Sent: s.sent.Load(), Won: s.won.Load()}
+}
+
+// Hedge sends the query to a second replica when the first hasn't answered
+// within after, and uses whichever answers first, cancelling the other. It
+// trades extra replica load for the tail latency of slow reads such as
+// dashboard queries, and has no effect on queries that go to the primary or
+// when fewer than two replicas are healthy.
+func (m *Model) Hedge(after time.Duration) *Model {
+	m.hedgeAfter = after
+	return m
+}
+
+// hedgeTarget returns the replica to hedge a read on pool with, or nil. It
+// is the next healthy replica after pool, leaving the round-robin of
+// target undisturbed.
+func (m *Model) hedgeTarget(pool *connPool) *connPool {
+	if m.hedgeAfter <= 0 || pool == m.db.primary {
+		return nil
+	}
+
+	for i, replica := range m.db.replicas {
+		if replica != pool {
+			continue
+		}
+		for j := 1; j < len(m.db.replicas); j++ {
+			if next := m.db.replicas[(i+j)%len(m.db.replicas)]; !next.down.Load() {
+				return next
+			}
+		}
+	}
+	return nil
+}
+
+// openRows opens the rows of a query on pool. release frees the connection
+// slot it holds once the rows are closed.
+func (m *Model) openRows(ctx context.Context, pool *connPool, op string, query string, args []interface{}) (rows *sql.Rows, release func(), err error) {
+	release, err = pool.limiter.acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only opening the rows is retried: once scan has consumed rows a retry
+	// could hand them to the caller twice. Writes returning rows, such as
+	// Create, are retried like the ones runExec runs.
+	retryable := isRetryableWrite
+	if op == "query" {
+		retryable = IsTransient
+	}
+	err = m.db.withRetry(ctx, retryable, func() error {
+		stmt, releaseStmt, err := m.prepareQuery(pool, query)
+		if err != nil {
+			return fmt.Errorf("prepare query error: %w", err)
+		}
+		defer releaseStmt()
+
+		rows, err = stmt.QueryContext(ctx, args...)
+		if err != nil {
+			return fmt.Errorf("%s error: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return rows, release, nil
+}
+
+// hedgeAttempt is the outcome of opening the rows on one replica
+type hedgeAttempt struct {
+	rows    *sql.Rows
+	release func()
+	err     error
+	// index is the position of the attempt in the order they were sent
+	index int
+}
+
+// openHedged opens the rows on pool, and on hedge too when pool hasn't
+// answered within the model's hedge delay. The first rows opened win; the
+// other attempt is cancelled and its rows closed in the background. An
+// error is only returned once every attempt sent has failed, except when
+// pool fails before the hedge is sent.
+func (m *Model) openHedged(ctx context.Context, pool *connPool, hedge *connPool, op string, query string, args []interface{}) (*sql.Rows, func(), error) {
+	results := make(chan hedgeAttempt, 2)
+	var cancels []context.CancelFunc
+	send := func(pool *connPool) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			rows, release, err := m.openRows(attemptCtx, pool, op, query, args)
+			results <- hedgeAttempt{rows: rows, release: release, err: err, index: index}
+		}()
+	}
+
+	send(pool)
+	timer := time.NewTimer(m.hedgeAfter)
+	defer timer.Stop()
+
+	var err error
+	for pending := 1; pending > 0; {
+		select {
+		case <-timer.C:
+			m.db.hedges.sent.Add(1)
+			send(hedge)
+			pending++
+		case attempt := <-results:
+			pending--
+			if attempt.err != nil {
+				cancels[attempt.index]()
+				if err == nil {
+					err = attempt.err
+				}
+				if len(cancels) == 1 {
+					return nil, nil, err
+				}
+				continue
+			}
+
+			if attempt.index > 0 {
+				m.db.hedges.won.Add(1)
+			}
+			for i, cancel := range cancels {
+				if i != attempt.index {
+					cancel()
+				}
+			}
+			go discardAttempts(results, pending)
+
+			// The rows are read under the context of the attempt, so it is
+			// only cancelled once they are closed
+			cancel := cancels[attempt.index]
+			return attempt.rows, func() {
+				attempt.release()
+				cancel()
+			}, nil
+		}
+	}
+	return nil, nil, err
+}
+
+// discardAttempts closes the rows of the n attempts that lost the race
+func discardAttempts(results <-chan hedgeAttempt, n int) {
+	for ; n > 0; n-- {
+		if attempt := <-results; attempt.err == nil {
+			attempt.rows.Close()
+			attempt.release()
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+)
+
+// HookType identifies the point in a write operation where a hook runs
+type HookType string
+
+const (
+	BeforeCreate HookType = "before_create"
+	AfterCreate  HookType = "after_create"
+	BeforeUpdate HookType = "before_update"
+	AfterUpdate  HookType = "after_update"
+	BeforeDelete HookType = "before_delete"
+	AfterDelete  HookType = "after_delete"
+)
+
+// HookFunc is called with the record data of the operation. Before hooks may
+// modify data to change what gets written. Create hooks receive the inserted
+// columns (before) and the returned row (after), update hooks receive the
+// updated columns, and delete hooks receive nil.
+type HookFunc func(ctx context.Context, data map[string]interface{}) error
+
+// RegisterHook registers fn to run on every operation of the given type
+// against table. Hooks run in registration order and the first error aborts
+// the operation.
+func (db *Orm) RegisterHook(table string, hookType HookType, fn HookFunc) {
+	db.hooksMu.Lock()
+	defer db.hooksMu.Unlock()
+
+	if db.hooks[table] == nil {
+		db.hooks[table] = make(map[HookType][]HookFunc)
+	}
+	db.hooks[table][hookType] = append(db.hooks[table][hookType], fn)
+}
+
+// runHooks invokes the hooks registered for table and hookType
+func (db *Orm) runHooks(ctx context.Context, table string, hookType HookType, data map[string]interface{}) error {
+	db.hooksMu.RLock()
+	hooks := db.hooks[table][hookType]
+	db.hooksMu.RUnlock()
+
+	for _, fn := range hooks {
+		if err := fn(ctx, data); err != nil {
+			return fmt.Errorf("%s hook on %s: %w", hookType, table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestIncrementRunsUpdateHooks checks that Increment sets the columns
+// before update hooks add, such as a timestamp
+func TestIncrementRunsUpdateHooks(t *testing.T) {
+	db := dryRunOrm(t)
+	db.RegisterHook("tasks", BeforeUpdate, func(_ context.Context, data map[string]interface{}) error {
+		data["updated_at"] = "now"
+		return nil
+	})
+
+	_, err := db.Table("tasks").Where("id", "=", 7).Increment("views", 1)
+	var dryRun *DryRunError
+	if !errors.As(err, &dryRun) {
+		t.Fatalf("Increment = %v, want a dry run error", err)
+	}
+
+	if want := `UPDATE "tasks" SET "views" = "views" + $1, "updated_at" = $2 WHERE id = $3`; dryRun.Query != want {
+		t.Errorf("query = %s, want %s", dryRun.Query, want)
+	}
+	if want := []interface{}{1, "now", 7}; !reflect.DeepEqual(dryRun.Args, want) {
+		t.Errorf("args = %v, want %v", dryRun.Args, want)
+	}
+}
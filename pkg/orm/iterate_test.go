@@ -0,0 +1,93 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// rowsDriver is a database/sql driver whose queries return the rows
+// id = 1..n, n being the DSN, whatever the statement. LIMIT is ignored too,
+// so Chunk sizes must exceed n for Chunk to stop.
+type rowsDriver struct{}
+
+type rowsConn struct{ n int }
+
+type rowsStmt struct{ n int }
+
+type idRows struct{ n, next int }
+
+func (rowsDriver) Open(dsn string) (driver.Conn, error) {
+	n, err := strconv.Atoi(dsn)
+	return &rowsConn{n: n}, err
+}
+
+func (c *rowsConn) Prepare(string) (driver.Stmt, error) { return &rowsStmt{n: c.n}, nil }
+func (c *rowsConn) Close() error                        { return nil }
+func (c *rowsConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (s *rowsStmt) Close() error  { return nil }
+func (s *rowsStmt) NumInput() int { return -1 }
+func (s *rowsStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s *rowsStmt) Query([]driver.Value) (driver.Rows, error) { return &idRows{n: s.n}, nil }
+
+func (r *idRows) Columns() []string { return []string{"id"} }
+func (r *idRows) Close() error      { return nil }
+func (r *idRows) Next(dest []driver.Value) error {
+	if r.next >= r.n {
+		return io.EOF
+	}
+	r.next++
+	dest[0] = int64(r.next)
+	return nil
+}
+
+func init() {
+	sql.Register("orm-rows", rowsDriver{})
+}
+
+// singleConnOrm returns an ORM limited to one connection over a database
+// of three rows
+func singleConnOrm(t *testing.T) *Orm {
+	db, err := sql.Open("orm-rows", "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return New(db, Config{MaxOpenConns: 1, ConnAcquireTimeout: 50 * time.Millisecond})
+}
+
+// TestEachHoldsItsConnection documents that Each's callback can't query
+// the ORM while the rows are open, which Chunk allows
+func TestEachHoldsItsConnection(t *testing.T) {
+	db := singleConnOrm(t)
+	ctx := context.Background()
+
+	err := db.Table("tasks").WithContext(ctx).Each(func(row map[string]interface{}) error {
+		_, err := db.Table("tasks").WithContext(ctx).Where("id", "=", row["id"]).First()
+		return err
+	})
+	if !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("query in Each = %v, want ErrPoolExhausted", err)
+	}
+
+	var seen int
+	err = db.Table("tasks").WithContext(ctx).Chunk(10, func(rows []map[string]interface{}) error {
+		seen += len(rows)
+		_, err := db.Table("tasks").WithContext(ctx).Where("id", "=", rows[0]["id"]).First()
+		return err
+	})
+	if err != nil {
+		t.Errorf("query in Chunk = %v, want nil", err)
+	}
+	if seen == 0 {
+		t.Error("Chunk passed no rows")
+	}
+}
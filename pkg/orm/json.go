@@ -0,0 +1,51 @@
+package orm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// WhereJSON adds a WHERE clause on a value inside a JSON column. path is a
+// dot separated list of keys, e.g. WhereJSON("settings", "notifications.email", "=", "true").
+// Values are compared as text.
+func (m *Model) WhereJSON(column string, path string, operator string, value interface{}) *Model {
+	m.Where(column, operator, value)
+
+	where := &m.query.wheres[len(m.query.wheres)-1]
+	for _, key := range strings.Split(path, ".") {
+		if key = sanitizeColumn(key); key != "" {
+			where.jsonPath = append(where.jsonPath, key)
+		}
+	}
+	return m
+}
+
+// encodeJSON marshals value for a json/jsonb column
+func encodeJSON(value interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot encode %T as JSON: %v", ErrInvalidValue, value, err)
+	}
+	return string(encoded), nil
+}
+
+// decodeJSON unmarshals a json/jsonb column value into maps and slices,
+// returning the raw value if it isn't valid JSON
+func decodeJSON(value interface{}) interface{} {
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return value
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
@@ -0,0 +1,139 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/AyoubTahir/projects_management/pkg/reqctx"
+)
+
+// QueryEvent describes an executed query
+type QueryEvent struct {
+	Query    string
+	Args     []interface{}
+	Time     time.Time
+	Duration time.Duration
+	// Rows is the number of rows returned or affected, -1 when unknown
+	Rows   int64
+	Caller string
+	Err    error
+	Slow   bool
+	// RequestID and Route identify the HTTP request that ran the query,
+	// when its context carries them (see package reqctx)
+	RequestID string
+	Route     string
+}
+
+// QueryLogger receives executed queries. All queries are logged when
+// Config.QueryLog is set; queries slower than Config.SlowQueryThreshold are
+// always logged with Slow set.
+type QueryLogger interface {
+	LogQuery(ctx context.Context, event QueryEvent)
+}
+
+// LeveledLogger is the subset of pkg/logger.Logger used to log queries
+type LeveledLogger interface {
+	Info(format string, v ...interface{})
+	Warn(format string, v ...interface{})
+	Error(format string, v ...interface{})
+}
+
+// NewQueryLogger logs queries to l, failed queries at ERROR and slow
+// queries at WARN
+func NewQueryLogger(l LeveledLogger) QueryLogger {
+	return &leveledQueryLogger{logger: l}
+}
+
+type leveledQueryLogger struct {
+	logger LeveledLogger
+}
+
+func (l *leveledQueryLogger) LogQuery(_ context.Context, e QueryEvent) {
+	switch {
+	case e.Err != nil:
+		l.logger.Error("[ORM] query failed in %v (caller %s%s): %s args=%v: %v", e.Duration, e.Caller, requestFields(e), e.Query, e.Args, e.Err)
+	case e.Slow:
+		l.logger.Warn("[ORM] slow query %v, %d rows (caller %s%s): %s args=%v", e.Duration, e.Rows, e.Caller, requestFields(e), e.Query, e.Args)
+	default:
+		l.logger.Info("[ORM] query %v, %d rows (caller %s%s): %s args=%v", e.Duration, e.Rows, e.Caller, requestFields(e), e.Query, e.Args)
+	}
+}
+
+// requestFields formats the request the query ran for, if known
+func requestFields(e QueryEvent) string {
+	if e.RequestID == "" && e.Route == "" {
+		return ""
+	}
+	return fmt.Sprintf(", request %s, route %s", e.RequestID, e.Route)
+}
+
+// stdoutQueryLogger is used until a logger is set with SetQueryLogger
+type stdoutQueryLogger struct{}
+
+func (stdoutQueryLogger) LogQuery(_ context.Context, e QueryEvent) {
+	fmt.Printf("[ORM] Query (%v, %d rows, caller %s%s):\n%s\nArgs: %v\n", e.Duration, e.Rows, e.Caller, requestFields(e), e.Query, e.Args)
+	if e.Err != nil {
+		fmt.Printf("[ORM] Error: %v\n", e.Err)
+	}
+}
+
+// SetQueryLogger replaces the logger queries are reported to
+func (db *Orm) SetQueryLogger(l QueryLogger) {
+	db.queryLogger = l
+}
+
+// logQuery reports a finished query. rows and err point to the results of
+// the query so it can be deferred before they are known.
+func (db *Orm) logQuery(ctx context.Context, query string, args []interface{}, start time.Time, rows *int64, err *error) {
+	duration := time.Since(start)
+	slow := db.slowQueryThreshold > 0 && duration >= db.slowQueryThreshold
+	if !db.queryLog && !slow {
+		return
+	}
+
+	var requestID, route string
+	if ctx != nil {
+		requestID, route = reqctx.RequestID(ctx), reqctx.Route(ctx)
+	}
+	event := QueryEvent{
+		Query:     query,
+		Args:      args,
+		Time:      start,
+		Duration:  duration,
+		Rows:      *rows,
+		Caller:    queryCaller(),
+		Err:       *err,
+		Slow:      slow,
+		RequestID: requestID,
+		Route:     route,
+	}
+
+	if slow {
+		db.slowLog.add(event)
+	}
+	db.queryLogger.LogQuery(ctx, event)
+}
+
+var ormPackage = reflect.TypeOf(Orm{}).PkgPath() + "."
+
+// queryCaller returns the file:line of the first caller outside this package
+func queryCaller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, ormPackage) {
+			return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
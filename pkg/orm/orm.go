@@ -5,51 +5,85 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
 	ErrNoRows          = errors.New("no rows found")
 	ErrInvalidOperator = errors.New("invalid operator")
 	ErrInvalidValue    = errors.New("invalid value")
+	ErrInvalidOrderDir = errors.New("invalid order direction")
 )
 
 // DB represents the database connection
 type Orm struct {
 	*sql.DB
-	mu       sync.RWMutex
-	queryLog bool
-	prepared map[string]*sql.Stmt
+	dialect            Dialect
+	queryLog           bool
+	queryTimeout       time.Duration
+	queryLogger        QueryLogger
+	slowLog            *slowQueryLog
+	slowQueryThreshold time.Duration
+	tracer             trace.Tracer
+	maxRetries         int
+	retryBaseDelay     time.Duration
+	dryRun             atomic.Bool
+	pingInterval       time.Duration
+	primary            *connPool
+	replicas           []*connPool
+	nextReplica        uint64
+	hooksMu            sync.RWMutex
+	hooks              map[string]map[HookType][]HookFunc
+	cache              Cache
+	cacheHealth        cacheHealth
+	hedges             hedgeStats
+	scopesMu           sync.RWMutex
+	scopes             map[string][]globalScope
 }
 
 // Query represents a database query builder
 type Query struct {
 	table      string
+	from       *Model
 	selections []string
 	wheres     []whereClause
 	orWheres   []whereClause
 	joins      []joinClause
 	limit      int
 	offset     int
-	orderBy    string
-	orderDir   string
+	orders     []orderClause
+	rank       *fullTextSearch
 	groupBy    []string
 	having     []havingClause
+	lock       string
+	distinct   bool
+	unions     []unionClause
 }
 
 // Model represents a database model
 type Model struct {
-	db    *Orm
-	query Query
-	ctx   context.Context
+	db        *Orm
+	query     Query
+	ctx       context.Context
+	timeout   time.Duration
+	useWrites bool
+	cacheTTL  time.Duration
+	// hedgeAfter is the delay before a read is hedged, 0 when it isn't
+	hedgeAfter time.Duration
+	unscoped   map[string]bool
 }
 
 type whereClause struct {
 	column   string
 	operator string
 	value    interface{}
+	jsonPath []string
 }
 
 type joinClause struct {
@@ -59,11 +93,29 @@ type joinClause struct {
 	args      []interface{}
 }
 
+type unionClause struct {
+	all   bool
+	model *Model
+}
+
 type havingClause struct {
 	condition string
 	args      []interface{}
 }
 
+type orderClause struct {
+	column    string
+	direction string
+}
+
+// LockOption controls how a row lock behaves when rows are already locked
+type LockOption string
+
+const (
+	NoWait     LockOption = "NOWAIT"
+	SkipLocked LockOption = "SKIP LOCKED"
+)
+
 // Valid operators for where clauses
 var validOperators = map[string]bool{
 	"=":           true,
@@ -78,27 +130,71 @@ var validOperators = map[string]bool{
 	"NOT IN":      true,
 	"IS NULL":     true,
 	"IS NOT NULL": true,
+	"BETWEEN":     true,
 }
 
 // Config represents database configuration
 type Config struct {
-	MaxOpenConns    int
-	MaxIdleConns    int
-	ConnMaxLifetime time.Duration
-	QueryLog        bool
+	MaxOpenConns        int
+	MaxIdleConns        int
+	ConnMaxLifetime     time.Duration
+	QueryLog            bool
+	StmtCacheSize       int
+	Dialect             string
+	DefaultQueryTimeout time.Duration
+	SlowQueryThreshold  time.Duration
+	Tracing             bool
+	MaxRetries          int
+	RetryBaseDelay      time.Duration
+	DryRun              bool
+	PingInterval        time.Duration
+	ConnAcquireTimeout  time.Duration
 }
 
-// New creates a new ORM instance with configuration
-func New(db *sql.DB, config Config) *Orm {
-	db.SetMaxOpenConns(config.MaxOpenConns)
-	db.SetMaxIdleConns(config.MaxIdleConns)
-	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+// New creates a new ORM instance with configuration. db is the primary
+// used for writes; reads are spread over replicas when any are given.
+// It panics if config.Dialect names an unsupported dialect.
+func New(db *sql.DB, config Config, replicas ...*sql.DB) *Orm {
+	dialect, err := DialectFor(config.Dialect)
+	if err != nil {
+		panic(err)
+	}
+
+	pools := make([]*connPool, len(replicas))
+	for i, replica := range append([]*sql.DB{db}, replicas...) {
+		replica.SetMaxOpenConns(config.MaxOpenConns)
+		replica.SetMaxIdleConns(config.MaxIdleConns)
+		replica.SetConnMaxLifetime(config.ConnMaxLifetime)
 
-	return &Orm{
-		DB:       db,
-		queryLog: config.QueryLog,
-		prepared: make(map[string]*sql.Stmt),
+		if i > 0 {
+			pools[i-1] = newConnPool(replica, config)
+		}
 	}
+
+	orm := &Orm{
+		DB:                 db,
+		dialect:            dialect,
+		queryLog:           config.QueryLog,
+		queryTimeout:       config.DefaultQueryTimeout,
+		queryLogger:        stdoutQueryLogger{},
+		slowLog:            newSlowQueryLog(slowQueryLogSize),
+		slowQueryThreshold: config.SlowQueryThreshold,
+		tracer:             newTracer(config.Tracing),
+		maxRetries:         config.MaxRetries,
+		retryBaseDelay:     config.RetryBaseDelay,
+		pingInterval:       config.PingInterval,
+		primary:            newConnPool(db, config),
+		replicas:           pools,
+		hooks:              make(map[string]map[HookType][]HookFunc),
+		scopes:             make(map[string][]globalScope),
+	}
+	orm.dryRun.Store(config.DryRun)
+	return orm
+}
+
+// Dialect returns the SQL dialect the ORM generates queries for
+func (db *Orm) Dialect() Dialect {
+	return db.dialect
 }
 
 // WithContext adds context to the model
@@ -107,6 +203,99 @@ func (m *Model) WithContext(ctx context.Context) *Model {
 	return m
 }
 
+// Timeout bounds the execution time of this query, overriding the ORM's
+// default query timeout
+func (m *Model) Timeout(d time.Duration) *Model {
+	m.timeout = d
+	return m
+}
+
+// queryContext derives the context to execute the query with from ctx. The
+// model's timeout always applies; the ORM default only applies when the
+// caller's context has no deadline of its own.
+func (m *Model) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.timeout > 0 {
+		return context.WithTimeout(ctx, m.timeout)
+	}
+
+	if _, ok := ctx.Deadline(); !ok && m.db.queryTimeout > 0 {
+		return context.WithTimeout(ctx, m.db.queryTimeout)
+	}
+
+	return ctx, func() {}
+}
+
+// Clone returns a copy of the model whose query can be extended without
+// affecting the original, e.g. to run a count and a paginated fetch from
+// the same base query
+func (m *Model) Clone() *Model {
+	return &Model{
+		db:         m.db,
+		ctx:        m.ctx,
+		timeout:    m.timeout,
+		useWrites:  m.useWrites,
+		cacheTTL:   m.cacheTTL,
+		hedgeAfter: m.hedgeAfter,
+		unscoped:   cloneSet(m.unscoped),
+		query:      m.query.clone(),
+	}
+}
+
+// clone deep-copies the query clauses
+func (q Query) clone() Query {
+	c := q
+	c.selections = append([]string(nil), q.selections...)
+	c.wheres = cloneWheres(q.wheres)
+	c.orWheres = cloneWheres(q.orWheres)
+	c.groupBy = append([]string(nil), q.groupBy...)
+	c.orders = append([]orderClause(nil), q.orders...)
+
+	c.joins = make([]joinClause, len(q.joins))
+	for i, join := range q.joins {
+		join.args = append([]interface{}(nil), join.args...)
+		c.joins[i] = join
+	}
+
+	c.having = make([]havingClause, len(q.having))
+	for i, having := range q.having {
+		having.args = append([]interface{}(nil), having.args...)
+		c.having[i] = having
+	}
+
+	if q.from != nil {
+		c.from = q.from.Clone()
+	}
+
+	c.unions = make([]unionClause, len(q.unions))
+	for i, union := range q.unions {
+		c.unions[i] = unionClause{all: union.all, model: union.model.Clone()}
+	}
+
+	return c
+}
+
+func cloneWheres(wheres []whereClause) []whereClause {
+	cloned := make([]whereClause, len(wheres))
+	for i, where := range wheres {
+		if sub, ok := where.value.(*Model); ok {
+			where.value = sub.Clone()
+		}
+		cloned[i] = where
+	}
+	return cloned
+}
+
+func cloneSet(set map[string]bool) map[string]bool {
+	if set == nil {
+		return nil
+	}
+	cloned := make(map[string]bool, len(set))
+	for k, v := range set {
+		cloned[k] = v
+	}
+	return cloned
+}
+
 // Table initializes a new query for the given table
 func (db *Orm) Table(tableName string) *Model {
 	return &Model{
@@ -119,6 +308,14 @@ func (db *Orm) Table(tableName string) *Model {
 	}
 }
 
+// FromSubquery initializes a new query selecting from a derived table built
+// from sub, e.g. SELECT ... FROM (SELECT ...) AS alias
+func (db *Orm) FromSubquery(sub *Model, alias string) *Model {
+	m := db.Table(alias)
+	m.query.from = sub
+	return m
+}
+
 // Select adds columns to select
 func (m *Model) Select(columns ...string) *Model {
 	m.query.selections = sanitizeColumns(columns)
@@ -154,52 +351,180 @@ func (m *Model) addJoin(joinType string, table string, condition string, args ..
 
 // Where adds a WHERE clause with validation
 func (m *Model) Where(column string, operator string, value interface{}) *Model {
-	if !validOperators[strings.ToUpper(operator)] {
-		panic(ErrInvalidOperator)
-	}
-
-	m.query.wheres = append(m.query.wheres, whereClause{
-		column:   sanitizeColumn(column),
-		operator: strings.ToUpper(operator),
-		value:    value,
-	})
+	m.query.wheres = append(m.query.wheres, newWhereClause(column, operator, value))
 	return m
 }
 
 // OrWhere adds an OR WHERE clause with validation
 func (m *Model) OrWhere(column string, operator string, value interface{}) *Model {
-	if !validOperators[strings.ToUpper(operator)] {
+	m.query.orWheres = append(m.query.orWheres, newWhereClause(column, operator, value))
+	return m
+}
+
+// newWhereClause validates a condition of Where or OrWhere. It panics with
+// ErrInvalidOperator on an unknown operator, and with ErrInvalidValue when
+// the value of BETWEEN isn't a slice of its two bounds.
+func newWhereClause(column string, operator string, value interface{}) whereClause {
+	operator = strings.ToUpper(operator)
+	if !validOperators[operator] {
 		panic(ErrInvalidOperator)
 	}
 
-	m.query.orWheres = append(m.query.orWheres, whereClause{
+	if operator == "BETWEEN" {
+		bounds := reflect.ValueOf(value)
+		if (bounds.Kind() != reflect.Slice && bounds.Kind() != reflect.Array) || bounds.Len() != 2 {
+			panic(ErrInvalidValue)
+		}
+		value = []interface{}{bounds.Index(0).Interface(), bounds.Index(1).Interface()}
+	}
+
+	return whereClause{
 		column:   sanitizeColumn(column),
-		operator: strings.ToUpper(operator),
+		operator: operator,
 		value:    value,
+	}
+}
+
+// WhereIn adds a WHERE column IN (...) clause. values may be a slice or a
+// *Model, in which case it is embedded as a subquery.
+func (m *Model) WhereIn(column string, values interface{}) *Model {
+	return m.Where(column, "IN", values)
+}
+
+// WhereNotIn adds a WHERE column NOT IN (...) clause
+func (m *Model) WhereNotIn(column string, values interface{}) *Model {
+	return m.Where(column, "NOT IN", values)
+}
+
+// WhereNull adds a WHERE column IS NULL clause
+func (m *Model) WhereNull(column string) *Model {
+	return m.Where(column, "IS NULL", nil)
+}
+
+// WhereNotNull adds a WHERE column IS NOT NULL clause
+func (m *Model) WhereNotNull(column string) *Model {
+	return m.Where(column, "IS NOT NULL", nil)
+}
+
+// WhereBetween adds a WHERE column BETWEEN from AND to clause
+func (m *Model) WhereBetween(column string, from, to interface{}) *Model {
+	return m.Where(column, "BETWEEN", []interface{}{from, to})
+}
+
+// OrderBy adds a sort column. direction is "ASC" or "DESC" in any case and
+// defaults to ASC when empty; it panics with ErrInvalidOrderDir otherwise.
+func (m *Model) OrderBy(column string, direction string) *Model {
+	direction = strings.ToUpper(strings.TrimSpace(direction))
+	if direction == "" {
+		direction = "ASC"
+	}
+	if direction != "ASC" && direction != "DESC" {
+		panic(ErrInvalidOrderDir)
+	}
+
+	m.query.orders = append(m.query.orders, orderClause{
+		column:    sanitizeQualifiedColumn(column),
+		direction: direction,
 	})
 	return m
 }
 
-// Get executes the query and returns all matching records
-func (m *Model) Get() ([]map[string]interface{}, error) {
-	query, args := m.buildSelectQuery()
+// GroupBy adds grouping columns
+func (m *Model) GroupBy(columns ...string) *Model {
+	for _, column := range columns {
+		m.query.groupBy = append(m.query.groupBy, sanitizeQualifiedColumn(column))
+	}
+	return m
+}
 
-	if m.db.queryLog {
-		defer logQuery(query, args, time.Now())
+// Having adds a HAVING condition, ANDed with the previous ones. The
+// condition is raw SQL with ? placeholders for args, e.g.
+// Having("COUNT(*) > ?", 5), so user input must only be passed as args.
+func (m *Model) Having(condition string, args ...interface{}) *Model {
+	if strings.Count(condition, "?") != len(args) {
+		panic(ErrInvalidValue)
 	}
 
-	stmt, err := m.prepareQuery(query)
-	if err != nil {
-		return nil, fmt.Errorf("prepare query error: %w", err)
+	m.query.having = append(m.query.having, havingClause{condition: condition, args: args})
+	return m
+}
+
+// Limit caps the number of rows returned; 0 removes the cap
+func (m *Model) Limit(n int) *Model {
+	if n < 0 {
+		panic(ErrInvalidValue)
+	}
+	m.query.limit = n
+	return m
+}
+
+// Offset skips the first n rows
+func (m *Model) Offset(n int) *Model {
+	if n < 0 {
+		panic(ErrInvalidValue)
+	}
+	m.query.offset = n
+	return m
+}
+
+// Distinct removes duplicate rows from the result
+func (m *Model) Distinct() *Model {
+	m.query.distinct = true
+	return m
+}
+
+// Union combines the results of other with this query, removing duplicates.
+// Ordering and limits set on this model apply to the combined result.
+func (m *Model) Union(other *Model) *Model {
+	m.query.unions = append(m.query.unions, unionClause{model: other})
+	return m
+}
+
+// UnionAll combines the results of other with this query, keeping duplicates
+func (m *Model) UnionAll(other *Model) *Model {
+	m.query.unions = append(m.query.unions, unionClause{all: true, model: other})
+	return m
+}
+
+// ForUpdate locks the selected rows against concurrent updates until the
+// surrounding transaction ends
+func (m *Model) ForUpdate(opts ...LockOption) *Model {
+	return m.setLock("FOR UPDATE", opts)
+}
+
+// ForShare locks the selected rows against concurrent updates while still
+// allowing other transactions to read-lock them
+func (m *Model) ForShare(opts ...LockOption) *Model {
+	return m.setLock("FOR SHARE", opts)
+}
+
+func (m *Model) setLock(mode string, opts []LockOption) *Model {
+	if len(opts) > 0 {
+		mode = fmt.Sprintf("%s %s", mode, opts[0])
+	}
+	m.query.lock = mode
+	return m
+}
+
+// Get executes the query and returns all matching records
+func (m *Model) Get() ([]map[string]interface{}, error) {
+	query, args := m.buildSelectQuery()
+	if results, ok := m.cached(query, args); ok {
+		return results, nil
 	}
 
-	rows, err := stmt.QueryContext(m.ctx, args...)
+	var results []map[string]interface{}
+	err := m.runQuery("query", query, args, func(rows *sql.Rows) (int64, error) {
+		var err error
+		results, err = m.scanRows(rows)
+		return int64(len(results)), err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("query error: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	return m.scanRows(rows)
+	m.storeCached(query, args, results)
+	return results, nil
 }
 
 // First returns the first matching record
@@ -215,6 +540,119 @@ func (m *Model) First() (map[string]interface{}, error) {
 	return results[0], nil
 }
 
+// Exists reports whether any record matches the query
+func (m *Model) Exists() (bool, error) {
+	// Selecting a constant spares reading the columns, but every branch of
+	// a union must select as many columns, so unions are wrapped as built
+	inner := m.Clone()
+	if len(inner.query.unions) == 0 {
+		inner.query.selections = []string{"1"}
+	}
+	subQuery, args := inner.buildSelectQuery()
+	query := fmt.Sprintf("SELECT EXISTS(%s)", subQuery)
+
+	var exists bool
+	err := m.runQuery("query", query, args, func(rows *sql.Rows) (int64, error) {
+		if !rows.Next() {
+			return 0, rows.Err()
+		}
+		return 1, rows.Scan(&exists)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// Pluck returns the values of a single column for the matching records. The
+// column may be qualified with its table, e.g. Pluck("users.id").
+func (m *Model) Pluck(column string) ([]interface{}, error) {
+	column = sanitizeQualifiedColumn(column)
+
+	inner := m.Clone()
+	inner.query.selections = []string{column}
+	rows, err := inner.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows are keyed by the column name without its table
+	name := column[strings.LastIndex(column, ".")+1:]
+	values := make([]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = row[name]
+	}
+
+	return values, nil
+}
+
+// Chunk fetches the matching records in batches of size rows and passes each
+// batch to fn, stopping at the first error. Batches are paged with
+// LIMIT/OFFSET, so the query should be ordered for stable results. Each
+// batch is read in full before fn runs, so fn may run queries of its own.
+func (m *Model) Chunk(size int, fn func(rows []map[string]interface{}) error) error {
+	if size <= 0 {
+		return ErrInvalidValue
+	}
+
+	for offset := 0; ; offset += size {
+		page := m.Clone()
+		page.query.limit = size
+		page.query.offset = offset
+
+		rows, err := page.Get()
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		if err := fn(rows); err != nil {
+			return err
+		}
+
+		if len(rows) < size {
+			return nil
+		}
+	}
+}
+
+// Each streams the matching records one at a time to fn using a single
+// query, without loading the whole result set into memory.
+//
+// The query holds its connection, and its Config.MaxOpenConns slot, until
+// the last row is read, so fn must not run queries on the same ORM: with a
+// Config.ConnAcquireTimeout they fail with ErrPoolExhausted once the pool
+// is in use, always so with a single connection, and without one they may
+// wait forever. Use Chunk when fn needs the database.
+func (m *Model) Each(fn func(row map[string]interface{}) error) error {
+	query, args := m.buildSelectQuery()
+
+	return m.runQuery("query", query, args, func(rows *sql.Rows) (int64, error) {
+		columns, err := rows.ColumnTypes()
+		if err != nil {
+			return 0, err
+		}
+
+		var count int64
+		for rows.Next() {
+			row, err := scanRow(rows, columns)
+			if err != nil {
+				return count, err
+			}
+			count++
+
+			if err := fn(row); err != nil {
+				return count, err
+			}
+		}
+
+		return count, rows.Err()
+	})
+}
+
 // Create inserts a new record with better error handling
 func (m *Model) Create(data map[string]interface{}) (map[string]interface{}, error) {
 	if len(data) == 0 {
@@ -236,43 +674,65 @@ func (m *Model) Create(data map[string]interface{}) (map[string]interface{}, err
 		newData["updated_at"] = now
 	}
 
+	if err := m.db.runHooks(m.ctx, m.query.table, BeforeCreate, newData); err != nil {
+		return nil, err
+	}
+
 	columns := make([]string, 0, len(newData))
 	values := make([]interface{}, 0, len(newData))
 	placeholders := make([]string, 0, len(newData))
 
 	i := 1
 	for column, value := range newData {
-		columns = append(columns, sanitizeColumn(column))
+		value, err := encodeValue(m.db.dialect, value)
+		if err != nil {
+			return nil, err
+		}
+
+		columns = append(columns, m.db.dialect.Quote(sanitizeColumn(column)))
 		values = append(values, value)
-		placeholders = append(placeholders, fmt.Sprintf("$%d", i))
+		placeholders = append(placeholders, m.db.dialect.Placeholder(i))
 		i++
 	}
 
 	query := fmt.Sprintf(
-		"INSERT INTO %s (%s) VALUES (%s) RETURNING *",
-		m.query.table,
+		"INSERT INTO %s (%s) VALUES (%s)",
+		m.db.dialect.Quote(m.query.table),
 		strings.Join(columns, ", "),
 		strings.Join(placeholders, ", "),
 	)
 
-	if m.db.queryLog {
-		defer logQuery(query, values, time.Now())
+	result, err := m.insert(query, values)
+	if err != nil {
+		return nil, err
 	}
+	m.invalidateCache()
 
-	stmt, err := m.prepareQuery(query)
-	if err != nil {
-		return nil, fmt.Errorf("prepare query error: %w", err)
+	if err := m.db.runHooks(m.ctx, m.query.table, AfterCreate, result); err != nil {
+		return nil, err
 	}
 
-	rows, err := stmt.QueryContext(m.ctx, values...)
-	if err != nil {
-		return nil, fmt.Errorf("create error: %w", err)
+	return result, nil
+}
+
+// insert executes the INSERT query and returns the created row
+func (m *Model) insert(query string, values []interface{}) (map[string]interface{}, error) {
+	if !m.db.dialect.SupportsReturning() {
+		return m.createWithLastInsertID(query, values)
 	}
-	defer rows.Close()
+	query += " RETURNING *"
 
-	results, err := m.scanRows(rows)
+	var results []map[string]interface{}
+	err := m.runQuery("create", query, values, func(rows *sql.Rows) (int64, error) {
+		var err error
+		results, err = m.scanRows(rows)
+		if err != nil {
+			return 0, fmt.Errorf("scan error: %w", err)
+		}
+		return int64(len(results)), nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("scan error: %w", err)
+		return nil, err
 	}
 
 	if len(results) == 0 {
@@ -282,18 +742,49 @@ func (m *Model) Create(data map[string]interface{}) (map[string]interface{}, err
 	return results[0], nil
 }
 
+// createWithLastInsertID inserts a record on dialects without RETURNING and
+// reloads it by its generated id
+func (m *Model) createWithLastInsertID(query string, values []interface{}) (map[string]interface{}, error) {
+	result, err := m.runExec("create", query, values)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("last insert id error: %w", err)
+	}
+
+	return m.db.Table(m.query.table).WithContext(m.ctx).UseWrites().Where("id", "=", id).First()
+}
+
 // Update updates matching records with improved error handling
 func (m *Model) Update(data map[string]interface{}) (int64, error) {
 	if len(data) == 0 {
 		return 0, ErrInvalidValue
 	}
 
-	sets := make([]string, 0, len(data))
-	values := make([]interface{}, 0, len(data))
+	// Copy so before hooks can modify the data without touching the input map
+	newData := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		newData[k] = v
+	}
+
+	if err := m.db.runHooks(m.ctx, m.query.table, BeforeUpdate, newData); err != nil {
+		return 0, err
+	}
+
+	sets := make([]string, 0, len(newData))
+	values := make([]interface{}, 0, len(newData))
 
 	i := 1
-	for column, value := range data {
-		sets = append(sets, fmt.Sprintf("%s = $%d", sanitizeColumn(column), i))
+	for column, value := range newData {
+		value, err := encodeValue(m.db.dialect, value)
+		if err != nil {
+			return 0, err
+		}
+
+		sets = append(sets, fmt.Sprintf("%s = %s", m.db.dialect.Quote(sanitizeColumn(column)), m.db.dialect.Placeholder(i)))
 		values = append(values, value)
 		i++
 	}
@@ -303,149 +794,350 @@ func (m *Model) Update(data map[string]interface{}) (int64, error) {
 
 	query := fmt.Sprintf(
 		"UPDATE %s SET %s%s",
-		m.query.table,
+		m.db.dialect.Quote(m.query.table),
 		strings.Join(sets, ", "),
 		whereClause,
 	)
 
-	if m.db.queryLog {
-		defer logQuery(query, values, time.Now())
+	result, err := m.runExec("update", query, values)
+	if err != nil {
+		return 0, err
+	}
+	m.invalidateCache()
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := m.db.runHooks(m.ctx, m.query.table, AfterUpdate, newData); err != nil {
+		return affected, err
+	}
+
+	return affected, nil
+}
+
+// Increment atomically adds by to column on the matching records. It runs
+// the update hooks like Update, see adjust.
+func (m *Model) Increment(column string, by interface{}) (int64, error) {
+	return m.adjust(column, "+", by)
+}
+
+// Decrement atomically subtracts by from column on the matching records. It
+// runs the update hooks like Update, see adjust.
+func (m *Model) Decrement(column string, by interface{}) (int64, error) {
+	return m.adjust(column, "-", by)
+}
+
+// adjust updates column relative to its current value in a single
+// statement, avoiding read-modify-write races on counters. The update hooks
+// receive the data without column, whose new value only the database
+// knows; columns before hooks add, such as a timestamp, are set too.
+func (m *Model) adjust(column string, operator string, by interface{}) (int64, error) {
+	data := make(map[string]interface{})
+	if err := m.db.runHooks(m.ctx, m.query.table, BeforeUpdate, data); err != nil {
+		return 0, err
+	}
+
+	col := m.db.dialect.Quote(sanitizeColumn(column))
+	sets := []string{fmt.Sprintf("%s = %s %s %s", col, col, operator, m.db.dialect.Placeholder(1))}
+	values := []interface{}{by}
+
+	i := 2
+	for column, value := range data {
+		value, err := encodeValue(m.db.dialect, value)
+		if err != nil {
+			return 0, err
+		}
+
+		sets = append(sets, fmt.Sprintf("%s = %s", m.db.dialect.Quote(sanitizeColumn(column)), m.db.dialect.Placeholder(i)))
+		values = append(values, value)
+		i++
 	}
 
-	stmt, err := m.prepareQuery(query)
+	whereClause, whereValues := m.buildWhereClause(i)
+	values = append(values, whereValues...)
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s%s",
+		m.db.dialect.Quote(m.query.table),
+		strings.Join(sets, ", "),
+		whereClause,
+	)
+
+	result, err := m.runExec("update", query, values)
 	if err != nil {
-		return 0, fmt.Errorf("prepare query error: %w", err)
+		return 0, err
 	}
+	m.invalidateCache()
 
-	result, err := stmt.ExecContext(m.ctx, values...)
+	affected, err := result.RowsAffected()
 	if err != nil {
-		return 0, fmt.Errorf("update error: %w", err)
+		return 0, err
+	}
+
+	if err := m.db.runHooks(m.ctx, m.query.table, AfterUpdate, data); err != nil {
+		return affected, err
 	}
 
-	return result.RowsAffected()
+	return affected, nil
 }
 
 // Delete deletes matching records with improved error handling
 func (m *Model) Delete() (int64, error) {
+	if err := m.db.runHooks(m.ctx, m.query.table, BeforeDelete, nil); err != nil {
+		return 0, err
+	}
+
 	whereClause, values := m.buildWhereClause(1)
 
 	query := fmt.Sprintf(
 		"DELETE FROM %s%s",
-		m.query.table,
+		m.db.dialect.Quote(m.query.table),
 		whereClause,
 	)
 
-	if m.db.queryLog {
-		defer logQuery(query, values, time.Now())
+	result, err := m.runExec("delete", query, values)
+	if err != nil {
+		return 0, err
 	}
+	m.invalidateCache()
 
-	stmt, err := m.prepareQuery(query)
+	affected, err := result.RowsAffected()
 	if err != nil {
-		return 0, fmt.Errorf("prepare query error: %w", err)
+		return 0, err
 	}
 
-	result, err := stmt.ExecContext(m.ctx, values...)
-	if err != nil {
-		return 0, fmt.Errorf("delete error: %w", err)
+	if err := m.db.runHooks(m.ctx, m.query.table, AfterDelete, nil); err != nil {
+		return affected, err
 	}
 
-	return result.RowsAffected()
+	return affected, nil
 }
 
 // Helper methods
 func (m *Model) buildSelectQuery() (string, []interface{}) {
-	var queryBuilder strings.Builder
-	var values []interface{}
+	return m.buildSelect(1)
+}
 
-	queryBuilder.WriteString(fmt.Sprintf(
-		"SELECT %s FROM %s",
-		strings.Join(m.query.selections, ", "),
-		m.query.table,
-	))
+// buildSelect builds the select query numbering its placeholders from
+// startIndex, so it can be embedded as a subquery of another query
+func (m *Model) buildSelect(startIndex int) (string, []interface{}) {
+	b := newSQLBuilder(m.db.dialect, startIndex, m.query.sizeHint())
+	m.writeSelect(b)
+	return b.String(), b.args
+}
+
+// buildWhereClause builds the WHERE clause of the model's conditions and
+// of the global scopes of its table, which always apply on top of them
+func (m *Model) buildWhereClause(startIndex int) (string, []interface{}) {
+	b := newSQLBuilder(m.db.dialect, startIndex, m.query.sizeHint())
+	m.writeWhereClause(b)
+	return b.String(), b.args
+}
+
+// writeSelect writes the select query, subqueries included, to b
+func (m *Model) writeSelect(b *sqlBuilder) {
+	b.WriteString("SELECT ")
+	if m.query.distinct {
+		b.WriteString("DISTINCT ")
+	}
+	b.writeList(m.query.selections)
+	b.WriteString(" FROM ")
+
+	if m.query.from != nil {
+		b.WriteByte('(')
+		m.query.from.writeSelect(b)
+		b.WriteString(") AS ")
+	}
+	b.WriteString(m.query.table)
 
 	// Add joins
 	for _, join := range m.query.joins {
+		b.WriteByte(' ')
+		b.WriteString(join.joinType)
+		b.WriteByte(' ')
+		b.WriteString(join.table)
 		if join.condition != "" {
-			queryBuilder.WriteString(fmt.Sprintf(" %s %s ON %s", join.joinType, join.table, join.condition))
-			values = append(values, join.args...)
-		} else {
-			queryBuilder.WriteString(fmt.Sprintf(" %s %s", join.joinType, join.table))
+			b.WriteString(" ON ")
+			b.WriteString(join.condition)
+			b.skip(join.args)
 		}
 	}
 
 	// Add where clauses
-	whereClause, whereValues := m.buildWhereClause(len(values) + 1)
-	queryBuilder.WriteString(whereClause)
-	values = append(values, whereValues...)
+	m.writeWhereClause(b)
 
 	// Add group by
 	if len(m.query.groupBy) > 0 {
-		queryBuilder.WriteString(" GROUP BY ")
-		queryBuilder.WriteString(strings.Join(m.query.groupBy, ", "))
+		b.WriteString(" GROUP BY ")
+		b.writeList(m.query.groupBy)
 	}
 
 	// Add having
 	if len(m.query.having) > 0 {
-		queryBuilder.WriteString(" HAVING ")
+		b.WriteString(" HAVING ")
 		for i, having := range m.query.having {
 			if i > 0 {
-				queryBuilder.WriteString(" AND ")
+				b.WriteString(" AND ")
 			}
-			queryBuilder.WriteString(having.condition)
-			values = append(values, having.args...)
+			b.writeRaw(having.condition, having.args)
+		}
+	}
+
+	// Add unions; ORDER BY, LIMIT and OFFSET below apply to the combined result
+	for _, union := range m.query.unions {
+		if union.all {
+			b.WriteString(" UNION ALL (")
+		} else {
+			b.WriteString(" UNION (")
 		}
+		union.model.writeSelect(b)
+		b.WriteByte(')')
 	}
 
-	// Add order by
-	if m.query.orderBy != "" {
-		queryBuilder.WriteString(fmt.Sprintf(" ORDER BY %s %s", m.query.orderBy, m.query.orderDir))
+	// Add order by, the full-text rank first when ranking
+	if m.query.rank != nil || len(m.query.orders) > 0 {
+		b.WriteString(" ORDER BY ")
+	}
+	if m.query.rank != nil {
+		b.WriteString("ts_rank(")
+		b.WriteString(m.query.rank.vector)
+		b.WriteString(", plainto_tsquery(")
+		b.bind(m.query.rank.query)
+		b.WriteString(")) DESC")
+	}
+	for i, order := range m.query.orders {
+		if i > 0 || m.query.rank != nil {
+			b.WriteString(", ")
+		}
+		b.WriteString(order.column)
+		b.WriteByte(' ')
+		b.WriteString(order.direction)
 	}
 
 	// Add limit and offset
 	if m.query.limit > 0 {
-		queryBuilder.WriteString(fmt.Sprintf(" LIMIT %d", m.query.limit))
+		b.WriteString(" LIMIT ")
+		b.writeInt(m.query.limit)
 	}
 	if m.query.offset > 0 {
-		queryBuilder.WriteString(fmt.Sprintf(" OFFSET %d", m.query.offset))
+		b.WriteString(" OFFSET ")
+		b.writeInt(m.query.offset)
 	}
 
-	return queryBuilder.String(), values
+	// Add row locking
+	if m.query.lock != "" && m.db.dialect.SupportsRowLocking() {
+		b.WriteByte(' ')
+		b.WriteString(m.query.lock)
+	}
 }
 
-func (m *Model) buildWhereClause(startIndex int) (string, []interface{}) {
-	if len(m.query.wheres) == 0 && len(m.query.orWheres) == 0 {
-		return "", nil
+// writeWhereClause writes the WHERE clause of the model's conditions and
+// of the global scopes of its table, if there are any
+func (m *Model) writeWhereClause(b *sqlBuilder) {
+	scopeWheres, scopeOrWheres := m.globalScopeConditions()
+	hasConditions := len(m.query.wheres)+len(m.query.orWheres) > 0
+	hasScopes := len(scopeWheres)+len(scopeOrWheres) > 0
+
+	switch {
+	case hasConditions && hasScopes:
+		b.WriteString(" WHERE (")
+		b.writeConditions(m.query.wheres, m.query.orWheres)
+		b.WriteString(") AND (")
+		b.writeConditions(scopeWheres, scopeOrWheres)
+		b.WriteByte(')')
+	case hasScopes:
+		b.WriteString(" WHERE ")
+		b.writeConditions(scopeWheres, scopeOrWheres)
+	case hasConditions:
+		b.WriteString(" WHERE ")
+		b.writeConditions(m.query.wheres, m.query.orWheres)
+	}
+}
+
+// sizeHint estimates the length of the query, so building it rarely has to
+// grow the buffer
+func (q *Query) sizeHint() int {
+	size := 64 + len(q.table)
+	for _, selection := range q.selections {
+		size += len(selection) + 2
 	}
+	size += 32 * (len(q.wheres) + len(q.orWheres) + len(q.joins) + len(q.having))
+	size += 16 * len(q.orders)
+	return size
+}
 
-	var whereBuilder strings.Builder
-	var values []interface{}
-	whereBuilder.WriteString(" WHERE ")
+// write writes the condition using the dialect's placeholders and binds its
+// values
+func (w whereClause) write(b *sqlBuilder) {
+	column := w.column
+	if len(w.jsonPath) > 0 {
+		column = b.dialect.JSONExtract(w.column, w.jsonPath)
+	}
 
-	paramIndex := startIndex
+	if sub, ok := w.value.(*Model); ok {
+		b.WriteString(column)
+		b.WriteByte(' ')
+		b.WriteString(w.operator)
+		b.WriteString(" (")
+		sub.writeSelect(b)
+		b.WriteByte(')')
+		return
+	}
 
-	for i, where := range m.query.wheres {
-		if i > 0 {
-			whereBuilder.WriteString(" AND ")
+	switch w.operator {
+	case "@@":
+		b.WriteString(column)
+		b.WriteString(" @@ plainto_tsquery(")
+		b.bind(w.value)
+		b.WriteByte(')')
+		return
+	case "IS NULL", "IS NOT NULL":
+		b.WriteString(column)
+		b.WriteByte(' ')
+		b.WriteString(w.operator)
+		return
+	case "BETWEEN":
+		bounds := w.value.([]interface{})
+		b.WriteString(column)
+		b.WriteString(" BETWEEN ")
+		b.bind(bounds[0])
+		b.WriteString(" AND ")
+		b.bind(bounds[1])
+		return
+	case "IN", "NOT IN":
+		list := reflect.ValueOf(w.value)
+		if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+			break
+		}
+		if list.Len() == 0 {
+			panic(ErrInvalidValue)
 		}
-		whereBuilder.WriteString(fmt.Sprintf("%s %s $%d", where.column, where.operator, paramIndex))
-		values = append(values, where.value)
-		paramIndex++
-	}
 
-	for i, orWhere := range m.query.orWheres {
-		if len(m.query.wheres) > 0 || i > 0 {
-			whereBuilder.WriteString(" OR ")
+		b.WriteString(column)
+		b.WriteByte(' ')
+		b.WriteString(w.operator)
+		b.WriteString(" (")
+		for i := 0; i < list.Len(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.bind(list.Index(i).Interface())
 		}
-		whereBuilder.WriteString(fmt.Sprintf("%s %s $%d", orWhere.column, orWhere.operator, paramIndex))
-		values = append(values, orWhere.value)
-		paramIndex++
+		b.WriteByte(')')
+		return
 	}
 
-	return whereBuilder.String(), values
+	b.WriteString(column)
+	b.WriteByte(' ')
+	b.WriteString(w.operator)
+	b.WriteByte(' ')
+	b.bind(w.value)
 }
 
 func (m *Model) scanRows(rows *sql.Rows) ([]map[string]interface{}, error) {
-	columns, err := rows.Columns()
+	columns, err := rows.ColumnTypes()
 	if err != nil {
 		return nil, err
 	}
@@ -453,52 +1145,135 @@ func (m *Model) scanRows(rows *sql.Rows) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
 
 	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-
-		for i := range columns {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
+		row, err := scanRow(rows, columns)
+		if err != nil {
 			return nil, err
 		}
 
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			row[col] = values[i]
-		}
-
 		results = append(results, row)
 	}
 
 	return results, nil
 }
 
-func (m *Model) prepareQuery(query string) (*sql.Stmt, error) {
-	m.db.mu.RLock()
-	stmt, ok := m.db.prepared[query]
-	m.db.mu.RUnlock()
+// scanRow scans the current row into a column name to value map
+func scanRow(rows *sql.Rows, columns []*sql.ColumnType) (map[string]interface{}, error) {
+	values := make([]interface{}, len(columns))
+	valuePtrs := make([]interface{}, len(columns))
+
+	for i := range columns {
+		valuePtrs[i] = &values[i]
+	}
+
+	if err := rows.Scan(valuePtrs...); err != nil {
+		return nil, err
+	}
+
+	row := make(map[string]interface{})
+	for i, col := range columns {
+		row[col.Name()] = decodeValue(col, values[i])
+	}
+
+	return row, nil
+}
+
+// runQuery prepares and executes a query returning rows. scan consumes the
+// rows while they are open and returns how many it read, which is logged
+// along with the query.
+func (m *Model) runQuery(op string, query string, args []interface{}, scan func(rows *sql.Rows) (int64, error)) (err error) {
+	if m.db.dryRun.Load() {
+		return &DryRunError{Query: query, Args: args}
+	}
+
+	var count int64
+	defer m.db.logQuery(m.ctx, query, args, time.Now(), &count, &err)
+
+	ctx, endSpan := m.db.startSpan(m.ctx, op, m.query.table, query)
+	defer func() { endSpan(count, err) }()
+
+	ctx, cancel := m.queryContext(ctx)
+	defer cancel()
+
+	pool := m.target(op)
+	var rows *sql.Rows
+	var release func()
+	if hedge := m.hedgeTarget(pool); hedge != nil {
+		rows, release, err = m.openHedged(ctx, pool, hedge, op, query, args)
+	} else {
+		rows, release, err = m.openRows(ctx, pool, op, query, args)
+	}
+	if err != nil {
+		return err
+	}
+	defer release()
+	defer rows.Close()
+
+	count, err = scan(rows)
+	return err
+}
 
-	if ok {
-		return stmt, nil
+// runExec prepares and executes a statement that returns no rows
+func (m *Model) runExec(op string, query string, args []interface{}) (result sql.Result, err error) {
+	if m.db.dryRun.Load() {
+		return nil, &DryRunError{Query: query, Args: args}
 	}
 
-	m.db.mu.Lock()
-	defer m.db.mu.Unlock()
+	count := int64(-1)
+	defer m.db.logQuery(m.ctx, query, args, time.Now(), &count, &err)
 
-	// Double-check after acquiring write lock
-	if stmt, ok = m.db.prepared[query]; ok {
-		return stmt, nil
+	ctx, endSpan := m.db.startSpan(m.ctx, op, m.query.table, query)
+	defer func() { endSpan(count, err) }()
+
+	ctx, cancel := m.queryContext(ctx)
+	defer cancel()
+
+	pool := m.target(op)
+	release, err := pool.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
+
+	// Writes aren't retried on dropped connections, which may have run
+	// them already: an insert or increment would apply twice
+	err = m.db.withRetry(ctx, isRetryableWrite, func() error {
+		stmt, releaseStmt, err := m.prepareQuery(pool, query)
+		if err != nil {
+			return fmt.Errorf("prepare query error: %w", err)
+		}
+		defer releaseStmt()
 
-	stmt, err := m.db.PrepareContext(m.ctx, query)
+		result, err = stmt.ExecContext(ctx, args...)
+		if err != nil {
+			return fmt.Errorf("%s error: %w", op, err)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	m.db.prepared[query] = stmt
-	return stmt, nil
+	if affected, err := result.RowsAffected(); err == nil {
+		count = affected
+	}
+	return result, nil
+}
+
+// prepareQuery returns the prepared statement of query on pool and the func
+// releasing it, to call once the statement has run. Rows opened with it
+// stay valid after the release.
+func (m *Model) prepareQuery(pool *connPool, query string) (*sql.Stmt, func(), error) {
+	if stmt, release, ok := pool.prepared.get(query); ok {
+		return stmt, release, nil
+	}
+
+	stmt, err := pool.db.PrepareContext(m.ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stmt, release := pool.prepared.add(query, stmt)
+	return stmt, release, nil
 }
 
 func sanitizeColumn(column string) string {
@@ -510,6 +1285,15 @@ func sanitizeColumn(column string) string {
 	}, column)
 }
 
+// sanitizeQualifiedColumn sanitizes each part of a table.column reference
+func sanitizeQualifiedColumn(column string) string {
+	parts := strings.Split(column, ".")
+	for i, part := range parts {
+		parts[i] = sanitizeColumn(part)
+	}
+	return strings.Join(parts, ".")
+}
+
 func sanitizeTableName(table string) string {
 	return sanitizeColumn(table)
 }
@@ -522,27 +1306,32 @@ func sanitizeColumns(columns []string) []string {
 	return sanitized
 }
 
-func logQuery(query string, args []interface{}, start time.Time) {
-	duration := time.Since(start)
-	fmt.Printf("[ORM] Query (%v):\n%s\nArgs: %v\n", duration, query, args)
-}
-
 // Cleanup closes all prepared statements
 func (db *Orm) Cleanup() error {
-	db.mu.Lock()
-	defer db.mu.Unlock()
-
 	var errs []string
-	for query, stmt := range db.prepared {
-		if err := stmt.Close(); err != nil {
-			errs = append(errs, fmt.Sprintf("failed to close statement for query %q: %v", query, err))
+	for _, pool := range db.pools() {
+		if err := pool.prepared.clear(); err != nil {
+			errs = append(errs, err.Error())
 		}
 	}
 
-	db.prepared = make(map[string]*sql.Stmt)
-
 	if len(errs) > 0 {
-		return fmt.Errorf("cleanup errors: %s", strings.Join(errs, "; "))
+		return errors.New(strings.Join(errs, "; "))
 	}
 	return nil
 }
+
+// StmtCacheStats returns the prepared statement cache counters summed over
+// the primary and the replicas
+func (db *Orm) StmtCacheStats() StmtCacheStats {
+	var total StmtCacheStats
+	for _, pool := range db.pools() {
+		stats := pool.prepared.stats()
+		total.Size += stats.Size
+		total.Capacity += stats.Capacity
+		total.Hits += stats.Hits
+		total.Misses += stats.Misses
+		total.Evictions += stats.Evictions
+	}
+	return total
+}
@@ -0,0 +1,57 @@
+// Package rediscache implements orm.Cache on Redis so cached query results
+// and their invalidation are shared between instances.
+package rediscache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tablePrefix prefixes the sets holding the keys cached for each table
+const tablePrefix = "orm:table:"
+
+type Cache struct {
+	client *redis.Client
+}
+
+func New(client *redis.Client) *Cache {
+	return &Cache{client: client}
+}
+
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores the value and adds its key to the set of each table. The sets
+// live as long as their longest lived entry.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tables []string) error {
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, value, ttl)
+		for _, table := range tables {
+			pipe.SAdd(ctx, tablePrefix+table, key)
+			pipe.ExpireGT(ctx, tablePrefix+table, ttl)
+			pipe.ExpireNX(ctx, tablePrefix+table, ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+func (c *Cache) InvalidateTable(ctx context.Context, table string) error {
+	keys, err := c.client.SMembers(ctx, tablePrefix+table).Result()
+	if err != nil {
+		return err
+	}
+
+	return c.client.Del(ctx, append(keys, tablePrefix+table)...).Err()
+}
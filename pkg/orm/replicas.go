@@ -0,0 +1,61 @@
+package orm
+
+import (
+	"database/sql"
+	"sync/atomic"
+)
+
+// connPool is a database handle with its own prepared statement cache, since
+// statements are bound to the *sql.DB they were prepared on
+type connPool struct {
+	db       *sql.DB
+	prepared *stmtCache
+	limiter  *connLimiter
+	// down is set by the pinger while the database can't be reached
+	down atomic.Bool
+}
+
+func newConnPool(db *sql.DB, config Config) *connPool {
+	return &connPool{
+		db:       db,
+		prepared: newStmtCache(config.StmtCacheSize),
+		limiter:  newConnLimiter(config.MaxOpenConns, config.ConnAcquireTimeout),
+	}
+}
+
+// UseWrites forces the query to run on the primary, e.g. to read a record
+// right after writing it without replication lag
+func (m *Model) UseWrites() *Model {
+	m.useWrites = true
+	return m
+}
+
+// target returns the pool a statement runs on. Plain reads are spread over
+// the healthy replicas round-robin; writes, locking reads, queries marked
+// with UseWrites and reads while every replica is down go to the primary.
+func (m *Model) target(op string) *connPool {
+	if op != "query" || m.useWrites || m.query.lock != "" {
+		return m.db.primary
+	}
+
+	for range m.db.replicas {
+		n := atomic.AddUint64(&m.db.nextReplica, 1)
+		if replica := m.db.replicas[(n-1)%uint64(len(m.db.replicas))]; !replica.down.Load() {
+			return replica
+		}
+	}
+	return m.db.primary
+}
+
+// Replicas returns the read replica handles given to New
+func (db *Orm) Replicas() []*sql.DB {
+	replicas := make([]*sql.DB, len(db.replicas))
+	for i, pool := range db.replicas {
+		replicas[i] = pool.db
+	}
+	return replicas
+}
+
+func (db *Orm) pools() []*connPool {
+	return append([]*connPool{db.primary}, db.replicas...)
+}
@@ -0,0 +1,229 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// primaryKey is the column Repo uses to find, update and delete records
+const primaryKey = "id"
+
+// Repo provides CRUD for the struct type T on top of the map based API.
+// Fields are mapped to columns through their `db` tag; untagged fields and
+// fields tagged `db:"-"` are ignored. The field tagged `db:"id"` is the
+// primary key.
+type Repo[T any] struct {
+	db     *Orm
+	table  string
+	fields []repoField
+	pk     *repoField
+}
+
+type repoField struct {
+	column string
+	index  []int
+}
+
+// NewRepo returns a repository of T records stored in table. It panics if
+// T isn't a struct.
+func NewRepo[T any](db *Orm, table string) *Repo[T] {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("orm: Repo type %s is not a struct", typ))
+	}
+
+	r := &Repo[T]{db: db, table: sanitizeTableName(table)}
+	r.fields = repoFields(typ, nil)
+	for i := range r.fields {
+		if r.fields[i].column == primaryKey {
+			r.pk = &r.fields[i]
+		}
+	}
+	return r
+}
+
+// repoFields collects the tagged fields of typ, including those of
+// embedded structs
+func repoFields(typ reflect.Type, index []int) []repoField {
+	var fields []repoField
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		column, _, _ := strings.Cut(field.Tag.Get("db"), ",")
+		if field.Anonymous && column == "" && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, repoFields(field.Type, fieldIndex)...)
+			continue
+		}
+		if !field.IsExported() || column == "" || column == "-" {
+			continue
+		}
+
+		fields = append(fields, repoField{column: sanitizeColumn(column), index: fieldIndex})
+	}
+	return fields
+}
+
+// Query returns a model on the repository table for queries Repo doesn't cover
+func (r *Repo[T]) Query(ctx context.Context) *Model {
+	return r.db.Table(r.table).WithContext(ctx)
+}
+
+// Find returns the record with the given primary key or ErrNoRows
+func (r *Repo[T]) Find(ctx context.Context, id interface{}) (*T, error) {
+	row, err := r.Query(ctx).Where(primaryKey, "=", id).First()
+	if err != nil {
+		return nil, err
+	}
+
+	record := new(T)
+	if err := r.scan(record, row); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// FindBy returns every record whose column equals value
+func (r *Repo[T]) FindBy(ctx context.Context, column string, value interface{}) ([]T, error) {
+	rows, err := r.Query(ctx).Where(column, "=", value).Get()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]T, len(rows))
+	for i, row := range rows {
+		if err := r.scan(&records[i], row); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// Save inserts record when its primary key is zero and updates it
+// otherwise. Inserted records are refreshed from the created row, so the
+// generated id and timestamps are set on return.
+func (r *Repo[T]) Save(ctx context.Context, record *T) error {
+	if r.pk == nil {
+		return fmt.Errorf("%w: %s has no %q field", ErrInvalidValue, r.table, primaryKey)
+	}
+
+	value := reflect.ValueOf(record).Elem()
+	id := value.FieldByIndex(r.pk.index)
+
+	if id.IsZero() {
+		row, err := r.Query(ctx).Create(r.values(value, true))
+		if err != nil {
+			return err
+		}
+		return r.scan(record, row)
+	}
+
+	data := r.values(value, false)
+	if _, ok := data["updated_at"]; ok {
+		data["updated_at"] = time.Now()
+	}
+
+	if _, err := r.Query(ctx).Where(primaryKey, "=", id.Interface()).Update(data); err != nil {
+		return err
+	}
+	return r.scan(record, data)
+}
+
+// Delete deletes the record with the given primary key, returning
+// ErrNoRows when there is none
+func (r *Repo[T]) Delete(ctx context.Context, id interface{}) error {
+	affected, err := r.Query(ctx).Where(primaryKey, "=", id).Delete()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNoRows
+	}
+	return nil
+}
+
+// values maps the record fields to columns, leaving out the primary key.
+// Inserts also leave out zero timestamps so Create fills them in, and
+// updates never overwrite created_at.
+func (r *Repo[T]) values(record reflect.Value, insert bool) map[string]interface{} {
+	data := make(map[string]interface{}, len(r.fields))
+	for _, f := range r.fields {
+		field := record.FieldByIndex(f.index)
+
+		switch {
+		case f.column == primaryKey:
+			continue
+		case insert && (f.column == "created_at" || f.column == "updated_at") && field.IsZero():
+			continue
+		case !insert && f.column == "created_at":
+			continue
+		}
+		data[f.column] = field.Interface()
+	}
+	return data
+}
+
+// scan copies the row columns into the matching fields of record
+func (r *Repo[T]) scan(record *T, row map[string]interface{}) error {
+	value := reflect.ValueOf(record).Elem()
+	for _, f := range r.fields {
+		columnValue, ok := row[f.column]
+		if !ok {
+			continue
+		}
+		if err := assign(value.FieldByIndex(f.index), columnValue); err != nil {
+			return fmt.Errorf("%s.%s: %w", r.table, f.column, err)
+		}
+	}
+	return nil
+}
+
+// assign stores a scanned column value in field, converting between the
+// driver's types and the field type where needed
+func assign(field reflect.Value, value interface{}) error {
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := assign(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type().AssignableTo(field.Type()):
+		field.Set(v)
+		return nil
+	case field.Kind() == reflect.String && v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		field.SetString(string(value.([]byte)))
+		return nil
+	case field.Kind() != reflect.String && v.Kind() != reflect.String && v.Type().ConvertibleTo(field.Type()):
+		field.Set(v.Convert(field.Type()))
+		return nil
+	}
+
+	// Decoded JSON and array columns into structs, maps and slices
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%w: cannot assign %T to %s", ErrInvalidValue, value, field.Type())
+	}
+	if err := json.Unmarshal(encoded, field.Addr().Interface()); err != nil {
+		return fmt.Errorf("%w: cannot assign %T to %s", ErrInvalidValue, value, field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand"
+	"syscall"
+	"time"
+)
+
+// Postgres SQLSTATE codes worth retrying: the statement was rolled back
+// only because of concurrent activity
+var retryableSQLStates = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+}
+
+// IsTransient reports whether err is a serialization failure, deadlock or
+// dropped connection that may succeed when the statement is retried. A
+// connection dropped mid-statement may have run it, so only reads should be
+// retried on those; see isRetryableWrite.
+func IsTransient(err error) bool {
+	return isRetryableWrite(err) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isRetryableWrite reports whether err leaves a statement safe to run again
+// even when it isn't idempotent: the server rolled it back, or the driver
+// didn't send it
+func isRetryableWrite(err error) bool {
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) && retryableSQLStates[stateErr.SQLState()] {
+		return true
+	}
+	return errors.Is(err, driver.ErrBadConn)
+}
+
+// withRetry runs fn, retrying the failures retryable accepts up to
+// Config.MaxRetries times with jittered exponential backoff starting at
+// Config.RetryBaseDelay
+func (db *Orm) withRetry(ctx context.Context, retryable func(error) bool, fn func() error) error {
+	delay := db.retryBaseDelay
+
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= db.maxRetries || !retryable(err) {
+			return err
+		}
+
+		wait := delay
+		if delay > 0 {
+			wait = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+
+		delay *= 2
+	}
+}
@@ -0,0 +1,70 @@
+package orm
+
+// ScopeFunc is a reusable query fragment
+type ScopeFunc func(m *Model) *Model
+
+type globalScope struct {
+	name string
+	fn   ScopeFunc
+}
+
+// Scope applies reusable query fragments, e.g.
+// db.Table("tasks").Scope(Active, AssignedTo(userID)).Get()
+func (m *Model) Scope(fns ...ScopeFunc) *Model {
+	for _, fn := range fns {
+		m = fn(m)
+	}
+	return m
+}
+
+// RegisterGlobalScope applies fn to every query, update and delete on
+// table, e.g. to filter rows by the tenant stored in the query context.
+// The scope's conditions are ANDed with the query's own, so they hold even
+// when the query uses OrWhere. Only the conditions a global scope adds are
+// used; joins, ordering and limits are ignored.
+func (db *Orm) RegisterGlobalScope(table string, name string, fn ScopeFunc) {
+	db.scopesMu.Lock()
+	defer db.scopesMu.Unlock()
+
+	db.scopes[table] = append(db.scopes[table], globalScope{name: name, fn: fn})
+}
+
+// WithoutGlobalScopes disables the named global scopes for this query, or
+// all of them when no name is given
+func (m *Model) WithoutGlobalScopes(names ...string) *Model {
+	if m.unscoped == nil {
+		m.unscoped = make(map[string]bool)
+	}
+	if len(names) == 0 {
+		m.unscoped["*"] = true
+	}
+	for _, name := range names {
+		m.unscoped[name] = true
+	}
+	return m
+}
+
+// globalScopeConditions returns the conditions the global scopes of the
+// model's table add
+func (m *Model) globalScopeConditions() ([]whereClause, []whereClause) {
+	if m.unscoped["*"] {
+		return nil, nil
+	}
+
+	m.db.scopesMu.RLock()
+	scopes := m.db.scopes[m.query.table]
+	m.db.scopesMu.RUnlock()
+
+	if len(scopes) == 0 {
+		return nil, nil
+	}
+
+	scoped := m.db.Table(m.query.table).WithContext(m.ctx)
+	for _, scope := range scopes {
+		if !m.unscoped[scope.name] {
+			scoped = scope.fn(scoped)
+		}
+	}
+
+	return scoped.query.wheres, scoped.query.orWheres
+}
@@ -0,0 +1,55 @@
+package orm
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// slowQueryLogSize is the number of slow queries kept for SlowQueries
+const slowQueryLogSize = 500
+
+// slowQueryLog is a ring buffer of the most recent slow queries
+type slowQueryLog struct {
+	mu     sync.Mutex
+	events []QueryEvent
+	next   int
+}
+
+func newSlowQueryLog(size int) *slowQueryLog {
+	return &slowQueryLog{events: make([]QueryEvent, 0, size)}
+}
+
+func (l *slowQueryLog) add(event QueryEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.events) < cap(l.events) {
+		l.events = append(l.events, event)
+		return
+	}
+	l.events[l.next] = event
+	l.next = (l.next + 1) % len(l.events)
+}
+
+// SlowQueries returns up to limit of the slowest queries that finished
+// within the last window, slowest first. Only queries above
+// Config.SlowQueryThreshold are kept, and only the most recent ones.
+func (db *Orm) SlowQueries(window time.Duration, limit int) []QueryEvent {
+	since := time.Now().Add(-window)
+
+	db.slowLog.mu.Lock()
+	events := make([]QueryEvent, 0, len(db.slowLog.events))
+	for _, event := range db.slowLog.events {
+		if event.Time.After(since) {
+			events = append(events, event)
+		}
+	}
+	db.slowLog.mu.Unlock()
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Duration > events[j].Duration })
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events
+}
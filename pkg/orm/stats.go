@@ -0,0 +1,95 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PoolStats reports the state of one connection pool
+type PoolStats struct {
+	sql.DBStats
+	StmtCache StmtCacheStats
+	Acquire   AcquireStats
+	// Healthy is false while the background pinger can't reach the database
+	Healthy bool
+	// Saturation is the share of the open connection limit in use, 0 when
+	// the pool is unlimited
+	Saturation float64
+}
+
+// Stats reports the primary and replica pools
+type Stats struct {
+	Primary  PoolStats
+	Replicas []PoolStats
+	Hedges   HedgeStats
+}
+
+// Stats returns the connection pool and prepared statement cache stats of
+// the primary and every replica
+func (db *Orm) Stats() Stats {
+	stats := Stats{Primary: db.primary.stats(), Hedges: db.hedges.stats()}
+	for _, pool := range db.replicas {
+		stats.Replicas = append(stats.Replicas, pool.stats())
+	}
+	return stats
+}
+
+func (p *connPool) stats() PoolStats {
+	dbStats := p.db.Stats()
+
+	stats := PoolStats{
+		DBStats:   dbStats,
+		StmtCache: p.prepared.stats(),
+		Acquire:   p.limiter.stats(),
+		Healthy:   !p.down.Load(),
+	}
+	if dbStats.MaxOpenConnections > 0 {
+		stats.Saturation = float64(dbStats.InUse) / float64(dbStats.MaxOpenConnections)
+	}
+	return stats
+}
+
+// StartPinger pings every pool each Config.PingInterval until ctx is done.
+// Pools failing the ping are reported unhealthy and skipped for reads.
+// onChange is called with the pool name ("primary" or "replica-N") and the
+// ping error when a pool goes down, and with a nil error when it reconnects.
+func (db *Orm) StartPinger(ctx context.Context, onChange func(pool string, err error)) {
+	if db.pingInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(db.pingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				db.pingPools(ctx, onChange)
+			}
+		}
+	}()
+}
+
+func (db *Orm) pingPools(ctx context.Context, onChange func(pool string, err error)) {
+	for i, pool := range db.pools() {
+		pingCtx, cancel := context.WithTimeout(ctx, db.pingInterval)
+		err := pool.db.PingContext(pingCtx)
+		cancel()
+
+		if wasDown := pool.down.Swap(err != nil); wasDown != (err != nil) && onChange != nil {
+			onChange(poolName(i), err)
+		}
+	}
+}
+
+func poolName(i int) string {
+	if i == 0 {
+		return "primary"
+	}
+	return fmt.Sprintf("replica-%d", i)
+}
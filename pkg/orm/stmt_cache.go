@@ -0,0 +1,171 @@
+package orm
+
+import (
+	"container/list"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultStmtCacheSize is used when Config.StmtCacheSize is not set
+const DefaultStmtCacheSize = 500
+
+// StmtCacheStats reports the prepared statement cache usage
+type StmtCacheStats struct {
+	Size      int    `json:"size"`
+	Capacity  int    `json:"capacity"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+}
+
+// stmtCache is an LRU cache of prepared statements keyed by query string.
+// Statements are handed out with a release func: one evicted while in use
+// is only closed once its last user releases it, so eviction never closes
+// a statement under a running query.
+type stmtCache struct {
+	mu        sync.Mutex
+	capacity  int
+	items     map[string]*list.Element
+	order     *list.List
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type stmtEntry struct {
+	query string
+	stmt  *sql.Stmt
+	// users counts the callers that haven't released the statement yet
+	users int
+	// evicted is set once the entry left the cache; the statement is closed
+	// when it also has no users
+	evicted bool
+}
+
+func newStmtCache(capacity int) *stmtCache {
+	if capacity <= 0 {
+		capacity = DefaultStmtCacheSize
+	}
+
+	return &stmtCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// get returns the cached statement and marks it as recently used. The
+// statement must be released once the caller is done running it.
+func (c *stmtCache) get(query string) (*sql.Stmt, func(), bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[query]
+	if !ok {
+		c.misses++
+		return nil, nil, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(elem)
+	entry := elem.Value.(*stmtEntry)
+	return entry.stmt, c.use(entry), true
+}
+
+// add stores a statement, evicting the least recently used ones when the
+// cache is full. If another goroutine cached the same query first, the
+// given statement is closed and the cached one is returned instead. The
+// returned statement must be released like the ones get returns.
+func (c *stmtCache) add(query string, stmt *sql.Stmt) (*sql.Stmt, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[query]; ok {
+		stmt.Close()
+		c.order.MoveToFront(elem)
+		entry := elem.Value.(*stmtEntry)
+		return entry.stmt, c.use(entry)
+	}
+
+	entry := &stmtEntry{query: query, stmt: stmt}
+	c.items[query] = c.order.PushFront(entry)
+	release := c.use(entry)
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		evicted := oldest.Value.(*stmtEntry)
+		c.order.Remove(oldest)
+		delete(c.items, evicted.query)
+		c.evict(evicted)
+		c.evictions++
+	}
+
+	return stmt, release
+}
+
+// use counts a user of entry and returns the func releasing it. Must be
+// called with c.mu held.
+func (c *stmtCache) use(entry *stmtEntry) func() {
+	entry.users++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			entry.users--
+			if entry.evicted && entry.users == 0 {
+				entry.stmt.Close()
+			}
+		})
+	}
+}
+
+// evict marks an entry removed from the cache, closing its statement unless
+// it is still in use. Must be called with c.mu held.
+func (c *stmtCache) evict(entry *stmtEntry) error {
+	entry.evicted = true
+	if entry.users > 0 {
+		return nil
+	}
+	return entry.stmt.Close()
+}
+
+func (c *stmtCache) stats() StmtCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return StmtCacheStats{
+		Size:      c.order.Len(),
+		Capacity:  c.capacity,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+// clear empties the cache, closing every statement now or, for the ones
+// in use, when they are released
+func (c *stmtCache) clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []string
+	for _, elem := range c.items {
+		entry := elem.Value.(*stmtEntry)
+		if err := c.evict(entry); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to close statement for query %q: %v", entry.query, err))
+		}
+	}
+
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("cleanup errors: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
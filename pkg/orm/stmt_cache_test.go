@@ -0,0 +1,78 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowDriver is rowsDriver with queries taking a millisecond, long enough
+// for other goroutines to evict the statement being run
+type slowDriver struct{ rowsDriver }
+
+type slowConn struct{ *rowsConn }
+
+type slowStmt struct{ driver.Stmt }
+
+func (d slowDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.rowsDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return slowConn{conn.(*rowsConn)}, nil
+}
+
+func (c slowConn) Prepare(query string) (driver.Stmt, error) {
+	stmt, err := c.rowsConn.Prepare(query)
+	return slowStmt{stmt}, err
+}
+
+func (s slowStmt) Query(args []driver.Value) (driver.Rows, error) {
+	time.Sleep(time.Millisecond)
+	return s.Stmt.Query(args)
+}
+
+func init() {
+	sql.Register("orm-slow", slowDriver{})
+}
+
+// TestStmtCacheEvictionDuringQueries runs distinct queries concurrently
+// through a cache holding one statement, so statements are evicted while
+// other goroutines are still running them
+func TestStmtCacheEvictionDuringQueries(t *testing.T) {
+	db, err := sql.Open("orm-slow", "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	orm := New(db, Config{StmtCacheSize: 1})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 400)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				table := fmt.Sprintf("tasks_%d", (g+i)%4)
+				if _, err := orm.Table(table).WithContext(ctx).Get(); err != nil {
+					errs <- err
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("query during eviction: %v", err)
+	}
+	if stats := orm.primary.prepared.stats(); stats.Evictions == 0 {
+		t.Errorf("evictions = 0, want the queries to evict statements")
+	}
+}
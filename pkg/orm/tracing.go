@@ -0,0 +1,53 @@
+package orm
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/AyoubTahir/projects_management/pkg/orm"
+
+// startSpan starts a client span for a query when Config.Tracing is enabled.
+// The span uses the globally registered OpenTelemetry tracer provider, so
+// spans are dropped until the application installs one. The returned func
+// ends the span with the query outcome.
+func (db *Orm) startSpan(ctx context.Context, op string, table string, query string) (context.Context, func(rows int64, err error)) {
+	if db.tracer == nil {
+		return ctx, func(int64, error) {}
+	}
+
+	start := time.Now()
+	ctx, span := db.tracer.Start(ctx, "orm."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", db.dialect.Name()),
+			attribute.String("db.operation", op),
+			attribute.String("db.sql.table", table),
+			attribute.String("db.statement", query),
+		),
+	)
+
+	return ctx, func(rows int64, err error) {
+		span.SetAttributes(
+			attribute.Int64("db.rows", rows),
+			attribute.Float64("db.duration_ms", float64(time.Since(start))/float64(time.Millisecond)),
+		)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func newTracer(enabled bool) trace.Tracer {
+	if !enabled {
+		return nil
+	}
+	return otel.Tracer(tracerName)
+}
@@ -0,0 +1,57 @@
+package orm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// encodeValue converts a Go value for writing: maps and structs become JSON,
+// and slices of scalars become Postgres arrays (JSON on other dialects).
+// Slices holding structs, maps or slices become JSON too, since Postgres
+// arrays can't store them. Times, byte slices and values implementing
+// driver.Valuer are left to the driver.
+func encodeValue(dialect Dialect, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch value.(type) {
+	case driver.Valuer, time.Time, *time.Time, []byte, json.RawMessage:
+		return value, nil
+	}
+
+	typ := reflect.TypeOf(value)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Map, reflect.Struct:
+		return encodeJSON(value)
+	case reflect.Slice, reflect.Array:
+		if dialect.Name() == "postgres" && isScalarList(value) {
+			return encodeArray(value), nil
+		}
+		return encodeJSON(value)
+	}
+
+	return value, nil
+}
+
+// decodeValue converts json/jsonb and array column values into Go values
+func decodeValue(column *sql.ColumnType, value interface{}) interface{} {
+	typeName := strings.ToUpper(column.DatabaseTypeName())
+
+	switch {
+	case typeName == "JSON" || typeName == "JSONB":
+		return decodeJSON(value)
+	case strings.HasPrefix(typeName, "_"):
+		return decodeArray(column, value)
+	}
+
+	return value
+}
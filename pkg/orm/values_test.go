@@ -0,0 +1,76 @@
+package orm
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+// dryRunOrm returns a postgres ORM in dry-run mode, which captures
+// statements without opening the connection
+func dryRunOrm(t *testing.T) *Orm {
+	db, err := sql.Open("postgres", "host=localhost dbname=test sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return New(db, Config{DryRun: true})
+}
+
+// createArg returns the value Create sends for column
+func createArg(t *testing.T, db *Orm, column string, value interface{}) interface{} {
+	t.Helper()
+
+	_, err := db.Table("projects").Create(map[string]interface{}{column: value})
+	var dryRun *DryRunError
+	if !errors.As(err, &dryRun) {
+		t.Fatalf("Create error = %v, want a dry run", err)
+	}
+	// Create adds the timestamps, and columns come in map order
+	columns := dryRun.Query[strings.Index(dryRun.Query, "(")+1 : strings.Index(dryRun.Query, ")")]
+	for i, name := range strings.Split(columns, ", ") {
+		if name == db.Dialect().Quote(column) {
+			return dryRun.Args[i]
+		}
+	}
+	t.Fatalf("column %s missing from %s", column, dryRun.Query)
+	return nil
+}
+
+func TestCreateEncodesStructSlicesAsJSON(t *testing.T) {
+	type milestone struct {
+		Name string `json:"name"`
+		Done bool   `json:"done"`
+	}
+	db := dryRunOrm(t)
+
+	got := createArg(t, db, "milestones", []milestone{{Name: "alpha", Done: true}, {Name: "beta"}})
+	want := `[{"name":"alpha","done":true},{"name":"beta","done":false}]`
+	if got != want {
+		t.Errorf("[]struct encoded as %#v, want %#v", got, want)
+	}
+
+	got = createArg(t, db, "settings", []map[string]interface{}{{"theme": "dark"}})
+	if want := `[{"theme":"dark"}]`; got != want {
+		t.Errorf("[]map encoded as %#v, want %#v", got, want)
+	}
+
+	got = createArg(t, db, "tags", []interface{}{"go", map[string]interface{}{"id": 1}})
+	if want := `["go",{"id":1}]`; got != want {
+		t.Errorf("mixed []interface{} encoded as %#v, want %#v", got, want)
+	}
+}
+
+func TestCreateEncodesScalarSlicesAsArrays(t *testing.T) {
+	db := dryRunOrm(t)
+
+	for _, value := range []interface{}{[]string{"go", "sql"}, []int64{1, 2}, []interface{}{"go", 1}} {
+		got := createArg(t, db, "tags", value)
+		if _, isString := got.(string); isString {
+			t.Errorf("%#v encoded as JSON %v, want a Postgres array", value, got)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWhereBetween(t *testing.T) {
+	db := dryRunOrm(t)
+
+	query, args := db.Table("tasks").Where("priority", "between", []int{1, 5}).ToSQL()
+	if want := "SELECT tasks.* FROM tasks WHERE priority BETWEEN $1 AND $2"; query != want {
+		t.Errorf("query = %s, want %s", query, want)
+	}
+	if want := []interface{}{1, 5}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+
+	for _, value := range []interface{}{5, []int{1}, []int{1, 2, 3}} {
+		func() {
+			defer func() {
+				if err, _ := recover().(error); !errors.Is(err, ErrInvalidValue) {
+					t.Errorf("Where BETWEEN %v panicked with %v, want ErrInvalidValue", value, err)
+				}
+			}()
+			db.Table("tasks").Where("priority", "BETWEEN", value)
+		}()
+	}
+}
@@ -0,0 +1,74 @@
+// Package param extracts typed path parameters from requests routed by
+// gorilla/mux. Failures are returned as *Error so handlers can answer 400.
+package param
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Error reports a missing or malformed request parameter
+type Error struct {
+	// In is where the parameter was read from: "path" or "query"
+	In     string
+	Name   string
+	Reason string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid %s parameter %s: %s", e.In, e.Name, e.Reason)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// String returns a path parameter, failing when it is empty
+func String(r *http.Request, name string) (string, error) {
+	value := mux.Vars(r)[name]
+	if value == "" {
+		return "", &Error{In: "path", Name: name, Reason: "is required"}
+	}
+	return value, nil
+}
+
+// Int64 parses an integer path parameter
+func Int64(r *http.Request, name string) (int64, error) {
+	value, err := String(r, name)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, &Error{In: "path", Name: name, Reason: "must be an integer", Err: err}
+	}
+	return n, nil
+}
+
+// Parse converts a path parameter with parse, e.g.
+// param.Parse(r, "id", types.ParseUserID)
+func Parse[T any](r *http.Request, name string, parse func(string) (T, error)) (T, error) {
+	var zero T
+
+	value, err := String(r, name)
+	if err != nil {
+		return zero, err
+	}
+
+	parsed, err := parse(value)
+	if err != nil {
+		reason := err.Error()
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) {
+			reason = "must be an integer"
+		}
+		return zero, &Error{In: "path", Name: name, Reason: reason, Err: err}
+	}
+	return parsed, nil
+}
@@ -0,0 +1,91 @@
+// Package query extracts typed query string parameters. Missing parameters
+// take the given fallback; malformed ones are returned as *param.Error so
+// handlers can answer 400.
+package query
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AyoubTahir/projects_management/pkg/param"
+)
+
+func invalid(name, reason string, err error) error {
+	return &param.Error{In: "query", Name: name, Reason: reason, Err: err}
+}
+
+// String returns a query parameter or fallback when it is missing
+func String(r *http.Request, name string, fallback string) string {
+	if value := r.URL.Query().Get(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Int parses an integer query parameter
+func Int(r *http.Request, name string, fallback int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, invalid(name, "must be an integer", err)
+	}
+	return value, nil
+}
+
+// PositiveInt parses an integer query parameter that must be above zero
+func PositiveInt(r *http.Request, name string, fallback int) (int, error) {
+	value, err := Int(r, name, fallback)
+	if err != nil || value <= 0 {
+		return 0, invalid(name, "must be a positive integer", err)
+	}
+	return value, nil
+}
+
+// Int64 parses a 64-bit integer query parameter
+func Int64(r *http.Request, name string, fallback int64) (int64, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, invalid(name, "must be an integer", err)
+	}
+	return value, nil
+}
+
+// Bool parses a boolean query parameter such as "true", "false", "1" or "0"
+func Bool(r *http.Request, name string, fallback bool) (bool, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, invalid(name, "must be a boolean", err)
+	}
+	return value, nil
+}
+
+// Enum returns a query parameter that must be one of allowed, or "" when
+// it is missing
+func Enum(r *http.Request, name string, allowed ...string) (string, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return "", nil
+	}
+
+	for _, a := range allowed {
+		if raw == a {
+			return raw, nil
+		}
+	}
+	return "", invalid(name, "must be one of "+strings.Join(allowed, ", "), nil)
+}
@@ -0,0 +1,50 @@
+// Package reqctx stores request-scoped values in a context.Context behind
+// typed accessors, so middleware, handlers and the ORM share one set of
+// keys instead of defining their own.
+package reqctx
+
+import "context"
+
+type key int
+
+const (
+	requestIDKey key = iota
+	routeKey
+	localeKey
+)
+
+// WithRequestID attaches the request ID to ctx
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID, or "" outside of a request
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithRoute attaches the route that matched the request to ctx, as its
+// method and path template such as "GET /users/{id}"
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey, route)
+}
+
+// Route returns the route that matched the request, or "" outside of a
+// request
+func Route(ctx context.Context) string {
+	route, _ := ctx.Value(routeKey).(string)
+	return route
+}
+
+// WithLocale attaches the locales the client accepts to ctx, as an
+// Accept-Language value such as "fr-CA, en;q=0.8"
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey, locale)
+}
+
+// Locale returns the locales the client accepts, or "" when it sent none
+func Locale(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey).(string)
+	return locale
+}
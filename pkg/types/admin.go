@@ -0,0 +1,15 @@
+package types
+
+import "time"
+
+type SlowQuery struct {
+	Query      string        `json:"query"`
+	Args       []interface{} `json:"args"`
+	StartedAt  time.Time     `json:"startedAt"`
+	DurationMs float64       `json:"durationMs"`
+	Rows       int64         `json:"rows"`
+	Caller     string        `json:"caller"`
+	RequestID  string        `json:"requestId,omitempty"`
+	Route      string        `json:"route,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
@@ -0,0 +1,39 @@
+package types
+
+import "github.com/AyoubTahir/projects_management/pkg/orm"
+
+type Health struct {
+	Database DatabaseHealth `json:"database"`
+	// Cache is omitted when no query cache is configured
+	Cache *CacheHealth `json:"cache,omitempty"`
+	// Degraded lists the optional dependencies that are failing while the
+	// service keeps answering without them
+	Degraded []string `json:"degraded,omitempty"`
+}
+
+type CacheHealth struct {
+	Healthy bool  `json:"healthy"`
+	Errors  int64 `json:"errors"`
+}
+
+type DatabaseHealth struct {
+	Primary  PoolHealth   `json:"primary"`
+	Replicas []PoolHealth `json:"replicas,omitempty"`
+	// Hedges counts the reads hedged on a second replica
+	Hedges orm.HedgeStats `json:"hedges"`
+}
+
+type PoolHealth struct {
+	Healthy            bool               `json:"healthy"`
+	MaxOpenConnections int                `json:"maxOpenConnections"`
+	OpenConnections    int                `json:"openConnections"`
+	InUse              int                `json:"inUse"`
+	Idle               int                `json:"idle"`
+	WaitCount          int64              `json:"waitCount"`
+	WaitDurationMs     float64            `json:"waitDurationMs"`
+	Saturation         float64            `json:"saturation"`
+	AcquireWaitCount   int64              `json:"acquireWaitCount"`
+	AcquireWaitMs      float64            `json:"acquireWaitMs"`
+	AcquireTimeouts    int64              `json:"acquireTimeouts"`
+	StmtCache          orm.StmtCacheStats `json:"stmtCache"`
+}
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+)
+
+// UserID identifies a user. Using it instead of a raw int64 keeps user IDs
+// from being passed where another entity's ID is expected.
+type UserID int64
+
+// ParseUserID parses a user ID from a path or query parameter
+func ParseUserID(s string) (UserID, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if !UserID(id).Valid() {
+		return 0, fmt.Errorf("invalid user ID %d", id)
+	}
+	return UserID(id), nil
+}
+
+// Valid reports whether id can identify a stored user
+func (id UserID) Valid() bool {
+	return id > 0
+}
+
+func (id UserID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+func (id UserID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+
+func (id *UserID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case int64:
+		*id = UserID(v)
+	case []byte:
+		parsed, err := strconv.ParseInt(string(v), 10, 64)
+		if err != nil {
+			return fmt.Errorf("cannot scan %q into UserID: %w", v, err)
+		}
+		*id = UserID(parsed)
+	default:
+		return fmt.Errorf("cannot scan %T into UserID", src)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package types
+
+// Locales lists the locales and timezones users can choose from
+type Locales struct {
+	Locales   []string `json:"locales"`
+	Timezones []string `json:"timezones"`
+}
+
+// Version describes the running build
+type Version struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"buildTime,omitempty"`
+	// Modified reports uncommitted changes in the build's working tree
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
+
+// ChangelogEntry is a change to the public API
+type ChangelogEntry struct {
+	// Date is the day the change shipped, as YYYY-MM-DD
+	Date string `json:"date"`
+	// Type is one of added, changed, deprecated or removed
+	Type string `json:"type"`
+	// Endpoint is the affected route, or "*" for every route
+	Endpoint string `json:"endpoint"`
+	Summary  string `json:"summary"`
+}
+
+// Capabilities describes the optional features the server runs with and
+// its limits, so clients can adapt instead of assuming them
+type Capabilities struct {
+	// Features maps each optional feature to whether it is enabled:
+	// "admin" for the /admin endpoints, "queryCache" and "readReplicas"
+	// when reads may briefly lag behind writes
+	Features map[string]bool    `json:"features"`
+	Locales  []string           `json:"locales"`
+	Limits   CapabilitiesLimits `json:"limits"`
+}
+
+// CapabilitiesLimits are the limits clients should enforce up front
+type CapabilitiesLimits struct {
+	PasswordMinLength     int `json:"passwordMinLength"`
+	RequestTimeoutSeconds int `json:"requestTimeoutSeconds"`
+}
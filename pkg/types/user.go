@@ -1,7 +1,27 @@
 package types
 
+// Users without a timezone or locale get these; the default tags of
+// CreateUserPayload repeat them
+const (
+	DefaultTimezone = "UTC"
+	DefaultLocale   = "en"
+)
+
 type CreateUserPayload struct {
-	UserName string `json:"userName" validate:"required"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8,max=130"`
+	UserName string `json:"userName" sanitize:"trim,strip_control" validate:"required"`
+	Email    string `json:"email" sanitize:"trim,lower" validate:"required,email,unique_db=users.email"`
+	Password string `json:"password" validate:"required,password,max=130"`
+	Timezone string `json:"timezone,omitempty" sanitize:"trim" default:"UTC" validate:"timezone"`
+	Locale   string `json:"locale,omitempty" sanitize:"trim" default:"en" validate:"locale"`
+}
+
+// UserResponse is the public representation of a user
+type UserResponse struct {
+	ID        UserID `json:"id"`
+	UserName  string `json:"userName"`
+	Email     string `json:"email"`
+	Timezone  string `json:"timezone"`
+	Locale    string `json:"locale"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
 }
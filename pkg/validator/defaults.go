@@ -0,0 +1,177 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// defaultField is a struct field that has a default or may contain structs
+// that have some
+type defaultField struct {
+	index int
+	name  string
+	// value is the parsed default, invalid when the field has none
+	value reflect.Value
+	err   error
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ApplyDefaults sets the zero-valued fields of the struct s points to from
+// their `default` tag, e.g. `default:"20"`. Tags apply to strings, bools,
+// numbers, time.Duration and pointers to them; nil pointers get a pointer
+// to the default, while pointers to a zero value are left as sent. Nested
+// structs, pointers to them and slices of them get their defaults too.
+func (v *Validator) ApplyDefaults(s interface{}) error {
+	val := reflect.ValueOf(s)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return errors.New("defaults only apply to pointers to structs")
+	}
+
+	// Malformed defaults fail before any field is set
+	if err := v.checkDefaults(val.Elem().Type(), make(map[reflect.Type]bool)); err != nil {
+		return err
+	}
+
+	v.applyStructDefaults(val.Elem())
+	return nil
+}
+
+// checkDefaults reports the first malformed default of a struct type or of
+// the structs it holds
+func (v *Validator) checkDefaults(typ reflect.Type, seen map[reflect.Type]bool) error {
+	if seen[typ] {
+		return nil
+	}
+	seen[typ] = true
+
+	for _, field := range v.defaultFields(typ) {
+		if field.err != nil {
+			return fmt.Errorf("invalid default for field %s: %w", field.name, field.err)
+		}
+		if elem := elemType(typ.Field(field.index).Type); elem.Kind() == reflect.Struct {
+			if err := v.checkDefaults(elem, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// defaultFields returns the fields of a struct type ApplyDefaults visits,
+// parsing their defaults on first use
+func (v *Validator) defaultFields(typ reflect.Type) []defaultField {
+	if cached, ok := v.defaults.Load(typ); ok {
+		return cached.([]defaultField)
+	}
+
+	var fields []defaultField
+	for i := 0; i < typ.NumField(); i++ {
+		fieldType := typ.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		tag, ok := fieldType.Tag.Lookup("default")
+		if !ok {
+			if containsStruct(fieldType.Type) {
+				fields = append(fields, defaultField{index: i, name: fieldType.Name})
+			}
+			continue
+		}
+
+		field := defaultField{index: i, name: fieldType.Name}
+		field.value, field.err = parseDefault(fieldType.Type, tag)
+		fields = append(fields, field)
+	}
+
+	cached, _ := v.defaults.LoadOrStore(typ, fields)
+	return cached.([]defaultField)
+}
+
+// parseDefault converts a default tag to a value of typ, or of the type
+// it points to
+func parseDefault(typ reflect.Type, tag string) (reflect.Value, error) {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	value := reflect.New(typ).Elem()
+	switch {
+	case typ == durationType:
+		d, err := time.ParseDuration(tag)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetInt(int64(d))
+	case typ.Kind() == reflect.String:
+		value.SetString(tag)
+	case typ.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(tag)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetBool(b)
+	case value.CanInt():
+		n, err := strconv.ParseInt(tag, 10, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetInt(n)
+	case value.CanUint():
+		n, err := strconv.ParseUint(tag, 10, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetUint(n)
+	case value.CanFloat():
+		f, err := strconv.ParseFloat(tag, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetFloat(f)
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported type %s", typ)
+	}
+	return value, nil
+}
+
+func (v *Validator) applyStructDefaults(val reflect.Value) {
+	for _, field := range v.defaultFields(val.Type()) {
+		fieldVal := val.Field(field.index)
+		if !field.value.IsValid() {
+			v.applyNestedDefaults(fieldVal)
+			continue
+		}
+
+		if !fieldVal.IsZero() {
+			continue
+		}
+		if fieldVal.Kind() == reflect.Ptr {
+			ptr := reflect.New(fieldVal.Type().Elem())
+			ptr.Elem().Set(field.value)
+			fieldVal.Set(ptr)
+		} else {
+			fieldVal.Set(field.value)
+		}
+	}
+}
+
+// applyNestedDefaults applies the defaults of the structs val holds
+func (v *Validator) applyNestedDefaults(val reflect.Value) {
+	switch val.Kind() {
+	case reflect.Ptr:
+		if !val.IsNil() {
+			v.applyNestedDefaults(val.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			v.applyNestedDefaults(val.Index(i))
+		}
+	case reflect.Struct:
+		v.applyStructDefaults(val)
+	}
+}
@@ -0,0 +1,92 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type listOptions struct {
+	Page    int           `default:"1"`
+	Sort    string        `default:"created_at"`
+	Active  bool          `default:"true"`
+	Ratio   float64       `default:"0.5"`
+	Limit   uint          `default:"20"`
+	Timeout time.Duration `default:"5s"`
+	Archive *bool         `default:"true"`
+	Nested  []listFilter
+}
+
+type listFilter struct {
+	Op string `default:"eq"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	no := false
+
+	tests := []struct {
+		name string
+		in   listOptions
+		want listOptions
+	}{
+		{"zero values", listOptions{Nested: []listFilter{{}}}, listOptions{
+			Page: 1, Sort: "created_at", Active: true, Ratio: 0.5, Limit: 20,
+			Timeout: 5 * time.Second, Archive: boolPtr(true), Nested: []listFilter{{Op: "eq"}},
+		}},
+		{"provided values", listOptions{
+			Page: 3, Sort: "name", Ratio: 0.1, Limit: 5, Timeout: time.Second,
+			Archive: &no, Nested: []listFilter{{Op: "gt"}},
+		}, listOptions{
+			Page: 3, Sort: "name", Active: true, Ratio: 0.1, Limit: 5, Timeout: time.Second,
+			Archive: boolPtr(false), Nested: []listFilter{{Op: "gt"}},
+		}},
+	}
+
+	v := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.in
+			if err := v.ApplyDefaults(&got); err != nil {
+				t.Fatalf("ApplyDefaults = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ApplyDefaults = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDefaultsErrors(t *testing.T) {
+	malformed := struct {
+		Sort string `default:"name"`
+		Page int    `default:"first"`
+	}{}
+
+	if err := New().ApplyDefaults(&malformed); err == nil {
+		t.Error("ApplyDefaults with a malformed default = nil, want an error")
+	}
+	if malformed.Sort != "" {
+		t.Errorf("Sort = %q, want fields left unchanged on error", malformed.Sort)
+	}
+
+	if err := New().ApplyDefaults(malformed); err == nil {
+		t.Error("ApplyDefaults of a struct value = nil, want an error")
+	}
+}
+
+func TestDefaultsBeforeValidate(t *testing.T) {
+	payload := struct {
+		PerPage int `json:"per_page" default:"20" validate:"min=1,max=100"`
+	}{}
+
+	if got := failures(t, New(), &payload); got != nil {
+		t.Errorf("failures = %v, want none", got)
+	}
+	if payload.PerPage != 20 {
+		t.Errorf("PerPage = %d, want the default 20", payload.PerPage)
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,216 @@
+package validator
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Messages maps rule names to message templates, which may use the
+// {field} and {param} placeholders
+type Messages map[string]string
+
+// Translator localizes validation messages
+type Translator interface {
+	// Translate returns the message of err in locale, or false when it has
+	// no translation for it
+	Translate(locale string, err ValidationError) (string, bool)
+}
+
+// Catalog is a Translator backed by per-locale Messages
+type Catalog struct {
+	mu      sync.RWMutex
+	locales map[string]Messages
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{locales: make(map[string]Messages)}
+}
+
+// Register adds messages to locale, overriding existing templates for the
+// same rules. Locales are language tags such as "fr" or "pt-br".
+func (c *Catalog) Register(locale string, messages Messages) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	locale = strings.ToLower(locale)
+	if c.locales[locale] == nil {
+		c.locales[locale] = make(Messages)
+	}
+	for rule, template := range messages {
+		c.locales[locale][rule] = template
+	}
+}
+
+// Has reports whether locale has been registered
+func (c *Catalog) Has(locale string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, ok := c.locales[strings.ToLower(locale)]
+	return ok
+}
+
+// Locales returns the registered locales, sorted
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locales := make([]string, 0, len(c.locales))
+	for locale := range c.locales {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+func (c *Catalog) Translate(locale string, err ValidationError) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	template, ok := c.locales[strings.ToLower(locale)][err.Rule]
+	if !ok {
+		return "", false
+	}
+	return formatMessage(template, err.Field, err.Param), true
+}
+
+// DefaultLocale is the language of the messages the validator writes.
+// Clients preferring it get them unchanged, so translating stops there.
+const DefaultLocale = "en"
+
+// DefaultCatalog holds the bundled French messages and the DefaultLocale,
+// registered empty so that it is listed. Register more locales on it with
+// RegisterLocale.
+var DefaultCatalog = NewCatalog()
+
+// RegisterLocale adds messages for locale to DefaultCatalog
+func RegisterLocale(locale string, messages Messages) {
+	DefaultCatalog.Register(locale, messages)
+}
+
+// Localize returns the errors with their messages translated to the
+// preferred locale of acceptLanguage, an Accept-Language header value, that
+// DefaultCatalog has a translation for. Messages keep their text when they
+// have no translation, when they were registered with RegisterMessage or
+// RegisterFieldMessage, and when DefaultLocale is preferred to any locale
+// translating them.
+func (e ValidationErrors) Localize(acceptLanguage string) ValidationErrors {
+	return e.Translate(DefaultCatalog, acceptLanguage)
+}
+
+// Translate is Localize with another Translator
+func (e ValidationErrors) Translate(t Translator, acceptLanguage string) ValidationErrors {
+	locales := preferredLocales(acceptLanguage)
+	if len(locales) == 0 {
+		return e
+	}
+
+	translated := make(ValidationErrors, len(e))
+	for i, err := range e {
+		if err.registered {
+			translated[i] = err
+			continue
+		}
+		for _, locale := range locales {
+			if locale == DefaultLocale {
+				break
+			}
+			if message, ok := t.Translate(locale, err); ok {
+				err.Message = message
+				break
+			}
+		}
+		translated[i] = err
+	}
+	return translated
+}
+
+// preferredLocales returns the languages of an Accept-Language header by
+// decreasing preference, each regional tag such as "fr-ca" followed by its
+// language
+func preferredLocales(acceptLanguage string) []string {
+	type preference struct {
+		tag string
+		q   float64
+	}
+
+	var preferences []preference
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			preferences = append(preferences, preference{tag: strings.ToLower(tag), q: q})
+		}
+	}
+	sort.SliceStable(preferences, func(i, j int) bool { return preferences[i].q > preferences[j].q })
+
+	locales := make([]string, 0, len(preferences))
+	for _, p := range preferences {
+		locales = append(locales, p.tag)
+		if language, _, ok := strings.Cut(p.tag, "-"); ok {
+			locales = append(locales, language)
+		}
+	}
+	return locales
+}
+
+func init() {
+	RegisterLocale(DefaultLocale, Messages{})
+
+	RegisterLocale("fr", Messages{
+		"required":        "ce champ est obligatoire",
+		"required_if":     "ce champ est obligatoire",
+		"required_unless": "ce champ est obligatoire",
+		"required_with":   "ce champ est obligatoire",
+		"password":        "le mot de passe est trop faible",
+		"notnil":          "ce champ ne peut pas être nul",
+		"email":           "format d'adresse e-mail invalide",
+		"url":             "format d'URL invalide",
+		"alpha":           "ce champ ne doit contenir que des lettres",
+		"alphanum":        "ce champ ne doit contenir que des lettres et des chiffres",
+		"int":             "ce champ doit être un nombre entier",
+		"numeric":         "ce champ ne doit contenir que des chiffres",
+		"lowercase":       "ce champ doit être en minuscules",
+		"uppercase":       "ce champ doit être en majuscules",
+		"slug":            "ce champ doit être un identifiant en minuscules",
+		"uuid":            "format d'UUID invalide",
+		"uuid4":           "format d'UUID v4 invalide",
+		"ipv4":            "adresse IPv4 invalide",
+		"ipv6":            "adresse IPv6 invalide",
+		"cidr":            "notation CIDR invalide",
+		"mac":             "adresse MAC invalide",
+		"hostname":        "nom d'hôte invalide",
+		"port":            "le port doit être compris entre 1 et 65535",
+		"phone":           "numéro de téléphone invalide",
+		"timezone":        "fuseau horaire invalide",
+		"locale":          "langue non prise en charge",
+		"min":             "doit être au moins {param}",
+		"max":             "ne doit pas dépasser {param}",
+		"len":             "la longueur doit être exactement {param}",
+		"maxbytes":        "la taille ne doit pas dépasser {param} octets",
+		"range":           "la valeur doit être comprise entre {param}",
+		"oneof":           "ce champ doit être l'une des valeurs : {param}",
+		"pattern":         "ce champ ne correspond pas au format attendu",
+		"datetime":        "format de date et heure invalide",
+		"future":          "la date doit être dans le futur",
+		"past":            "la date doit être dans le passé",
+		"iso8601":         "date ISO 8601 invalide",
+		"after":           "la date doit être postérieure à {param}",
+		"before":          "la date doit être antérieure à {param}",
+		"after_field":     "la date doit être postérieure à {param}",
+		"before_field":    "la date doit être antérieure à {param}",
+		"unique":          "la liste ne doit contenir que des valeurs uniques",
+		"unique_db":       "cette valeur est déjà utilisée",
+	})
+}
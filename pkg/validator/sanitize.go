@@ -0,0 +1,169 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// SanitizeFunc rewrites a string field
+type SanitizeFunc func(string) string
+
+// sanitizers are the built-in `sanitize` tag entries
+var sanitizers = map[string]SanitizeFunc{
+	"trim":          strings.TrimSpace,
+	"lower":         strings.ToLower,
+	"upper":         strings.ToUpper,
+	"escape_html":   html.EscapeString,
+	"strip_control": stripControl,
+}
+
+// sanitizeField is a struct field that has sanitizers or may contain
+// structs that have some
+type sanitizeField struct {
+	index      int
+	sanitizers []string
+}
+
+// RegisterSanitizer registers a custom sanitizer for `sanitize` tags
+func (v *Validator) RegisterSanitizer(name string, fn SanitizeFunc) {
+	v.customSanitizers[name] = fn
+}
+
+// Sanitize rewrites the string fields of the struct s points to with the
+// sanitizers of their `sanitize` tag, applied in order, e.g.
+// `sanitize:"trim,lower"`. Tags apply to string, *string and []string
+// fields; nested structs, pointers to them and slices of them are
+// sanitized too. The built-in sanitizers are trim, lower, upper,
+// escape_html and strip_control, which removes control characters.
+func (v *Validator) Sanitize(s interface{}) error {
+	val := reflect.ValueOf(s)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return errors.New("sanitization only works on pointers to structs")
+	}
+
+	// Unknown sanitizers fail before any field is rewritten
+	if err := v.checkSanitizers(val.Elem().Type(), make(map[reflect.Type]bool)); err != nil {
+		return err
+	}
+
+	v.sanitizeStruct(val.Elem())
+	return nil
+}
+
+// checkSanitizers reports the first unknown sanitizer of a struct type or
+// of the structs it holds
+func (v *Validator) checkSanitizers(typ reflect.Type, seen map[reflect.Type]bool) error {
+	if seen[typ] {
+		return nil
+	}
+	seen[typ] = true
+
+	for _, field := range v.sanitizeFields(typ) {
+		for _, name := range field.sanitizers {
+			if _, ok := v.sanitizer(name); !ok {
+				return fmt.Errorf("unknown sanitizer %q", name)
+			}
+		}
+
+		if elem := elemType(typ.Field(field.index).Type); elem.Kind() == reflect.Struct {
+			if err := v.checkSanitizers(elem, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sanitizeFields returns the fields of a struct type Sanitize visits,
+// parsing their tags on first use
+func (v *Validator) sanitizeFields(typ reflect.Type) []sanitizeField {
+	if cached, ok := v.sanitized.Load(typ); ok {
+		return cached.([]sanitizeField)
+	}
+
+	var fields []sanitizeField
+	for i := 0; i < typ.NumField(); i++ {
+		fieldType := typ.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		var names []string
+		if tag := fieldType.Tag.Get("sanitize"); tag != "" && tag != "-" {
+			names = strings.Split(tag, ",")
+		}
+		if names != nil || containsStruct(fieldType.Type) {
+			fields = append(fields, sanitizeField{index: i, sanitizers: names})
+		}
+	}
+
+	cached, _ := v.sanitized.LoadOrStore(typ, fields)
+	return cached.([]sanitizeField)
+}
+
+// containsStruct reports whether values of typ may hold structs to sanitize
+func containsStruct(typ reflect.Type) bool {
+	return elemType(typ).Kind() == reflect.Struct
+}
+
+// elemType returns the type typ holds through pointers, slices and arrays
+func elemType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+func (v *Validator) sanitizeStruct(val reflect.Value) {
+	for _, field := range v.sanitizeFields(val.Type()) {
+		v.sanitizeValue(val.Field(field.index), field.sanitizers)
+	}
+}
+
+// sanitizeValue applies the named sanitizers, which checkSanitizers has
+// resolved, to a string value, or descends into the structs val holds
+func (v *Validator) sanitizeValue(val reflect.Value, names []string) {
+	switch val.Kind() {
+	case reflect.String:
+		if names == nil {
+			return
+		}
+		str := val.String()
+		for _, name := range names {
+			fn, _ := v.sanitizer(name)
+			str = fn(str)
+		}
+		val.SetString(str)
+	case reflect.Ptr:
+		if !val.IsNil() {
+			v.sanitizeValue(val.Elem(), names)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			v.sanitizeValue(val.Index(i), names)
+		}
+	case reflect.Struct:
+		v.sanitizeStruct(val)
+	}
+}
+
+func (v *Validator) sanitizer(name string) (SanitizeFunc, bool) {
+	if fn, ok := v.customSanitizers[name]; ok {
+		return fn, true
+	}
+	fn, ok := sanitizers[name]
+	return fn, ok
+}
+
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,109 @@
+package validator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type sanitizedMember struct {
+	Email string `sanitize:"trim,lower"`
+}
+
+type sanitizedPayload struct {
+	Name     string `sanitize:"trim"`
+	Code     string `sanitize:"trim,upper"`
+	Bio      string `sanitize:"escape_html"`
+	Title    string `sanitize:"strip_control,trim"`
+	Nickname *string
+	Alias    *string  `sanitize:"trim"`
+	Tags     []string `sanitize:"trim,lower"`
+	Owner    sanitizedMember
+	Members  []*sanitizedMember
+	Raw      string
+}
+
+func TestSanitize(t *testing.T) {
+	alias, nickname := "  jd  ", "  kept  "
+	payload := sanitizedPayload{
+		Name:     "  Jane  ",
+		Code:     " fr ",
+		Bio:      `<b>"hi"</b>`,
+		Title:    " Lead\x00\n ",
+		Nickname: &nickname,
+		Alias:    &alias,
+		Tags:     []string{" Go ", "SQL"},
+		Owner:    sanitizedMember{Email: " Jane@Example.COM "},
+		Members:  []*sanitizedMember{{Email: " A@B.C "}, nil},
+		Raw:      "  raw  ",
+	}
+
+	if err := New().Sanitize(&payload); err != nil {
+		t.Fatalf("Sanitize = %v", err)
+	}
+
+	tests := []struct {
+		field string
+		got   interface{}
+		want  interface{}
+	}{
+		{"Name", payload.Name, "Jane"},
+		{"Code", payload.Code, "FR"},
+		{"Bio", payload.Bio, "&lt;b&gt;&#34;hi&#34;&lt;/b&gt;"},
+		{"Title", payload.Title, "Lead"},
+		{"Nickname", *payload.Nickname, "  kept  "},
+		{"Alias", *payload.Alias, "jd"},
+		{"Tags", payload.Tags, []string{"go", "sql"}},
+		{"Owner.Email", payload.Owner.Email, "jane@example.com"},
+		{"Members[0].Email", payload.Members[0].Email, "a@b.c"},
+		{"Raw", payload.Raw, "  raw  "},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %q, want %q", tt.field, tt.got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeBeforeValidate(t *testing.T) {
+	payload := struct {
+		Email string `json:"email" sanitize:"trim,lower" validate:"required,lowercase"`
+	}{Email: "   "}
+
+	if got, want := failures(t, New(), &payload), []string{"email:required"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("failures = %v, want %v", got, want)
+	}
+}
+
+func TestSanitizeCustom(t *testing.T) {
+	v := New()
+	v.RegisterSanitizer("squash", func(s string) string { return strings.Join(strings.Fields(s), " ") })
+
+	payload := struct {
+		Name string `sanitize:"squash"`
+	}{Name: " Jane   van  Doe "}
+	if err := v.Sanitize(&payload); err != nil {
+		t.Fatalf("Sanitize = %v", err)
+	}
+	if want := "Jane van Doe"; payload.Name != want {
+		t.Errorf("Name = %q, want %q", payload.Name, want)
+	}
+}
+
+func TestSanitizeErrors(t *testing.T) {
+	unknown := struct {
+		Name  string `sanitize:"trim"`
+		Email string `sanitize:"trim,nope"`
+	}{Name: " kept "}
+
+	if err := New().Sanitize(&unknown); err == nil || !strings.Contains(err.Error(), `"nope"`) {
+		t.Errorf("Sanitize with an unknown sanitizer = %v, want an error naming it", err)
+	}
+	if unknown.Name != " kept " {
+		t.Errorf("Name = %q, want fields left unchanged on error", unknown.Name)
+	}
+
+	if err := New().Sanitize(unknown); err == nil {
+		t.Error("Sanitize of a struct value = nil, want an error")
+	}
+}
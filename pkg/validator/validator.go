@@ -1,39 +1,214 @@
 package validator
 
 import (
+	"context"
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
+	"unicode/utf8"
 )
 
-// ValidationError represents a validation error
+// ValidationError represents a validation error. Field is the path of the
+// field as clients send it, using json tag names, e.g. "members[0].email".
 type ValidationError struct {
-	Field   string
-	Rule    string
-	Message string
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+	// registered is set when Message comes from RegisterMessage or
+	// RegisterFieldMessage, which translations don't override
+	registered bool
+}
+
+// ValidationErrors is the error Validate returns when validation fails
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Field + ": " + err.Message
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// ErrorsMap groups the error messages by field, for response bodies
+func (e ValidationErrors) ErrorsMap() map[string][]string {
+	fields := make(map[string][]string, len(e))
+	for _, err := range e {
+		fields[err.Field] = append(fields[err.Field], err.Message)
+	}
+	return fields
 }
 
 // CustomValidationFunc is a type for custom validation functions
 type CustomValidationFunc func(interface{}) bool
 
-// Validator represents the main validator struct
+// Validator represents the main validator struct. Configure it before use;
+// Validate is then safe for concurrent use, so one Validator can be shared
+// by every request.
 type Validator struct {
-	errors           []ValidationError
 	customValidators map[string]CustomValidationFunc
+	customSanitizers map[string]SanitizeFunc
+	messages         map[string]string
+	fieldMessages    map[string]map[string]string
+	passwordPolicy   PasswordPolicy
+	// maxErrors stops validation once that many errors were found; zero
+	// means no limit
+	maxErrors int
+	// graphemes makes string lengths count user-perceived characters
+	// instead of runes
+	graphemes bool
+	// uniqueChecker backs the unique_db rule
+	uniqueChecker UniqueChecker
+	// structs caches the parsed rules of each struct type
+	structs sync.Map
+	// sanitized caches the parsed sanitizers of each struct type
+	sanitized sync.Map
+	// defaults caches the parsed defaults of each struct type
+	defaults sync.Map
+}
+
+// validation is the state of a single Validate call
+type validation struct {
+	*Validator
+	errors ValidationErrors
+	// param is the parameter of the rule being checked, for {param}
+	param string
+	// parent is the struct whose fields are being validated, for rules
+	// that reference sibling fields
+	parent reflect.Value
+	// vars holds the values of a ValidateVars call, which rules reference
+	// instead of sibling fields
+	vars map[string]interface{}
+	// ctx is the context of rules that query the database
+	ctx context.Context
+	// err is the first error a rule failed to check with, which fails the
+	// whole validation
+	err error
+}
+
+// rule is a parsed `validate` tag entry such as "min=8"
+type rule struct {
+	name  string
+	param string
+}
+
+// structField is a validated field of a struct type
+type structField struct {
+	index int
+	name  string
+	rules []rule
+}
+
+// Option configures a Validator
+type Option func(*Validator)
+
+// WithFailFast stops validation at the first error
+func WithFailFast() Option {
+	return WithMaxErrors(1)
+}
+
+// WithMaxErrors stops validation once n errors were found, bounding the
+// errors a large payload can produce. n <= 0 means no limit.
+func WithMaxErrors(n int) Option {
+	return func(v *Validator) {
+		v.maxErrors = n
+	}
+}
+
+// WithGraphemeLength makes min, max and len count user-perceived
+// characters, so an emoji made of several code points, such as a flag or a
+// family, counts as one
+func WithGraphemeLength() Option {
+	return func(v *Validator) {
+		v.graphemes = true
+	}
+}
+
+// UniqueChecker looks values up for the unique_db rule
+type UniqueChecker interface {
+	// Unique reports whether no row of table has value in column
+	Unique(ctx context.Context, table, column string, value interface{}) (bool, error)
+}
+
+// WithUniqueChecker backs the unique_db rule with checker, typically a
+// repository querying the database
+func WithUniqueChecker(checker UniqueChecker) Option {
+	return func(v *Validator) {
+		v.uniqueChecker = checker
+	}
 }
 
 // New creates a new validator instance
-func New() *Validator {
-	return &Validator{
-		errors:           make([]ValidationError, 0),
+func New(opts ...Option) *Validator {
+	v := &Validator{
 		customValidators: make(map[string]CustomValidationFunc),
+		customSanitizers: make(map[string]SanitizeFunc),
+		messages:         make(map[string]string),
+		fieldMessages:    make(map[string]map[string]string),
+		passwordPolicy:   DefaultPasswordPolicy,
 	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// done reports whether the error limit has been reached or a rule failed
+// to check
+func (v *validation) done() bool {
+	return v.err != nil || v.maxErrors > 0 && len(v.errors) >= v.maxErrors
+}
+
+// PasswordPolicy is the strength the password rule requires
+type PasswordPolicy struct {
+	MinLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireNumber  bool
+	RequireSpecial bool
+}
+
+// DefaultPasswordPolicy requires 8 characters with an uppercase letter, a
+// lowercase letter, a number and a special character
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:      8,
+	RequireUpper:   true,
+	RequireLower:   true,
+	RequireNumber:  true,
+	RequireSpecial: true,
+}
+
+// SetPasswordPolicy sets the policy of the password rule
+func (v *Validator) SetPasswordPolicy(policy PasswordPolicy) {
+	v.passwordPolicy = policy
+}
+
+// RegisterMessage overrides the error message of rule for every field.
+// The template may use {field} for the field path and {param} for the rule
+// parameter, e.g. "{field} must be at least {param} characters".
+func (v *Validator) RegisterMessage(rule, template string) {
+	v.messages[rule] = template
+}
+
+// RegisterFieldMessage overrides the error message of rule for one field,
+// taking precedence over RegisterMessage. field is the path without slice
+// indexes, so "members.email" applies to every member.
+func (v *Validator) RegisterFieldMessage(field, rule, template string) {
+	if v.fieldMessages[field] == nil {
+		v.fieldMessages[field] = make(map[string]string)
+	}
+	v.fieldMessages[field][rule] = template
 }
 
 // RegisterCustomValidation registers a custom validation function
@@ -41,17 +216,33 @@ func (v *Validator) RegisterCustomValidation(name string, fn CustomValidationFun
 	v.customValidators[name] = fn
 }
 
-// GetErrors returns all validation errors
-func (v *Validator) GetErrors() []ValidationError {
-	return v.errors
+// Validate performs validation on the given struct and returns the
+// failures as ValidationErrors. Nested structs are validated too, with
+// errors reported under dotted paths such as "owner.email". Rules after
+// `dive` apply to each element of a slice, array or map field, so
+// `validate:"required,dive"` on a []MemberPayload validates every member
+// under paths such as "members[0].email". On maps, rules between `keys` and
+// `endkeys` right after `dive` apply to the keys, as in
+// `validate:"dive,keys,slug,endkeys,required"`. Given a pointer, Validate
+// first applies the struct's `sanitize` tags with Sanitize, then its
+// `default` tags with ApplyDefaults.
+func (v *Validator) Validate(s interface{}) error {
+	return v.ValidateContext(context.Background(), s)
 }
 
-// Validate performs validation on the given struct
-func (v *Validator) Validate(s interface{}) error {
-	v.errors = []ValidationError{} // Reset errors
+// ValidateContext is Validate with the context rules that query the
+// database, such as unique_db, run their queries with. When such a query
+// fails, its error is returned instead of ValidationErrors.
+func (v *Validator) ValidateContext(ctx context.Context, s interface{}) error {
 	val := reflect.ValueOf(s)
 
 	if val.Kind() == reflect.Ptr {
+		if err := v.Sanitize(s); err != nil {
+			return err
+		}
+		if err := v.ApplyDefaults(s); err != nil {
+			return err
+		}
 		val = val.Elem()
 	}
 
@@ -59,54 +250,387 @@ func (v *Validator) Validate(s interface{}) error {
 		return errors.New("validation only works on structs")
 	}
 
-	typ := val.Type()
+	run := &validation{Validator: v, ctx: ctx}
+	run.validateStruct("", val)
+
+	if run.err != nil {
+		return run.err
+	}
+	if len(run.errors) > 0 {
+		return run.errors
+	}
+
+	return nil
+}
+
+// ValidateVars validates request variables, such as path and query
+// parameters, with the rule language of `validate` tags. rules maps each
+// variable name to its rules, e.g. {"page": "omitempty,int,min=1"}. Empty
+// and missing variables are absent values, so only the presence rules apply
+// to them, while any other value, "0" included, is present. Variables with
+// an `int` rule are compared as numbers by min, max, range and oneof;
+// others as strings. A `default=VALUE` rule gives VALUE to the variable
+// when it is absent, before the other rules check it. The variables are
+// returned with their defaults in a new map; vars is left unchanged.
+func (v *Validator) ValidateVars(vars map[string]string, rules map[string]string) (map[string]string, error) {
+	run := &validation{Validator: v, vars: make(map[string]interface{}, len(vars)+len(rules))}
+	withDefaults := make(map[string]string, len(vars)+len(rules))
+	for name, raw := range vars {
+		run.vars[name] = varValue(raw, nil)
+		withDefaults[name] = raw
+	}
+
+	names := make([]string, 0, len(rules))
+	parsed := make(map[string][]rule, len(rules))
+	for name, tag := range rules {
+		names = append(names, name)
+		parsed[name] = parseRules(tag)
+
+		raw := vars[name]
+		if raw == "" {
+			raw = ruleParam(parsed[name], "default")
+			if raw != "" {
+				withDefaults[name] = raw
+			}
+		}
+		run.vars[name] = varValue(raw, parsed[name])
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if run.done() {
+			break
+		}
+		value := run.vars[name]
+		if hasRule(parsed[name], "omitempty") && value == nil {
+			continue
+		}
+		run.checkRules(name, value, parsed[name])
+	}
+
+	if run.err != nil {
+		return nil, run.err
+	}
+	if len(run.errors) > 0 {
+		return nil, run.errors
+	}
+
+	return withDefaults, nil
+}
 
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
+// varValue converts a request variable to the value its rules check: nil
+// when empty, an int64 when it has an `int` rule and is an integer, the
+// string otherwise
+func varValue(raw string, rules []rule) interface{} {
+	if raw == "" {
+		return nil
+	}
+	if hasRule(rules, "int") {
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return n
+		}
+	}
+	return raw
+}
+
+func hasRule(rules []rule, name string) bool {
+	for _, r := range rules {
+		if r.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleParam returns the parameter of the named rule, or "" without one
+func ruleParam(rules []rule, name string) string {
+	for _, r := range rules {
+		if r.name == name {
+			return r.param
+		}
+	}
+	return ""
+}
+
+// fields returns the validated fields of a struct type, parsing their tags
+// on first use
+func (v *Validator) fields(typ reflect.Type) []structField {
+	if cached, ok := v.structs.Load(typ); ok {
+		return cached.([]structField)
+	}
+
+	var fields []structField
+	for i := 0; i < typ.NumField(); i++ {
 		fieldType := typ.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
 
 		validateTag := fieldType.Tag.Get("validate")
-		if validateTag == "" {
+		if validateTag == "-" {
 			continue
 		}
 
-		rules := strings.Split(validateTag, ",")
-		for _, rule := range rules {
-			v.validateField(fieldType.Name, field.Interface(), rule)
+		fields = append(fields, structField{index: i, name: fieldName(fieldType), rules: parseRules(validateTag)})
+	}
+
+	cached, _ := v.structs.LoadOrStore(typ, fields)
+	return cached.([]structField)
+}
+
+func parseRules(tag string) []rule {
+	if tag == "" {
+		return nil
+	}
+
+	parts := strings.Split(tag, ",")
+	rules := make([]rule, len(parts))
+	for i, part := range parts {
+		rules[i].name, rules[i].param, _ = strings.Cut(part, "=")
+	}
+	return rules
+}
+
+// validateStruct validates every field of val, prefixing field names with
+// the path of val
+func (v *validation) validateStruct(prefix string, val reflect.Value) {
+	parent := v.parent
+	v.parent = val
+	defer func() { v.parent = parent }()
+
+	for _, field := range v.fields(val.Type()) {
+		if v.done() {
+			return
 		}
+		v.validateValue(prefix+field.name, val.Field(field.index), field.rules)
 	}
+}
 
-	if len(v.errors) > 0 {
-		return errors.New("validation failed")
+// fieldName returns the name clients use for a field: its json tag name,
+// or its Go name when it has none
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
 	}
+	return name
+}
 
-	return nil
+// validateValue applies rules to val, then descends into it: into its
+// fields when it is a struct and into its elements when a `dive` rule is
+// present
+func (v *validation) validateValue(fieldName string, val reflect.Value, rules []rule) {
+	var dive []rule
+	omitEmpty := false
+	for i, r := range rules {
+		if r.name == "omitempty" {
+			omitEmpty = true
+		}
+		if r.name == "dive" {
+			rules, dive = rules[:i], rules[i+1:]
+			break
+		}
+	}
+
+	value := fieldValue(val)
+	// omitempty skips every rule, and the fields or elements below, when
+	// no value was provided
+	if omitEmpty && isZero(value) {
+		return
+	}
+
+	v.checkRules(fieldName, value, rules)
+	if v.done() {
+		return
+	}
+
+	if dive != nil {
+		v.validateElements(fieldName, val, dive)
+		return
+	}
+
+	if nested, ok := nestedStruct(val); ok {
+		v.validateStruct(fieldName+".", nested)
+	}
 }
 
-// addError adds a validation error
-func (v *Validator) addError(field, rule, message string) {
+// checkRules checks value against each rule until the error limit is
+// reached
+func (v *validation) checkRules(fieldName string, value interface{}, rules []rule) {
+	for _, r := range rules {
+		if v.done() {
+			return
+		}
+		// Absent optional values only fail the presence rules
+		if value == nil && !presenceRules[r.name] {
+			continue
+		}
+		v.validateField(fieldName, value, r)
+	}
+}
+
+func isZero(value interface{}) bool {
+	return value == nil || reflect.ValueOf(value).IsZero()
+}
+
+// presenceRules are checked on nil pointers and null values; other rules
+// only check values that are present
+var presenceRules = map[string]bool{
+	"required":        true,
+	"required_if":     true,
+	"required_unless": true,
+	"required_with":   true,
+	"notnil":          true,
+}
+
+// fieldValue returns the value rules check: pointers are dereferenced and
+// sql.Null* style structs unwrapped through driver.Valuer. Nil pointers and
+// invalid Null values are returned as nil.
+func fieldValue(val reflect.Value) interface{} {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return nil
+	}
+
+	value := val.Interface()
+	if valuer, ok := value.(driver.Valuer); ok && val.Kind() == reflect.Struct {
+		unwrapped, err := valuer.Value()
+		if err != nil {
+			return value
+		}
+		return unwrapped
+	}
+	return value
+}
+
+// validateElements validates each element of a slice, array or map field
+func (v *validation) validateElements(fieldName string, val reflect.Value, rules []rule) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len() && !v.done(); i++ {
+			v.validateValue(fmt.Sprintf("%s[%d]", fieldName, i), val.Index(i), rules)
+		}
+	case reflect.Map:
+		var keyRules []rule
+		if len(rules) > 0 && rules[0].name == "keys" {
+			end := -1
+			for i, r := range rules {
+				if r.name == "endkeys" {
+					end = i
+					break
+				}
+			}
+			if end < 0 {
+				v.addError(fieldName, "keys", "keys rule without endkeys")
+				return
+			}
+			keyRules, rules = rules[1:end], rules[end+1:]
+		}
+
+		// Sorted so errors come in a stable order
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+
+		for _, key := range keys {
+			if v.done() {
+				return
+			}
+			path := fmt.Sprintf("%s[%v]", fieldName, key.Interface())
+			if keyRules != nil {
+				v.validateValue(path, key, keyRules)
+			}
+			v.validateValue(path, val.MapIndex(key), rules)
+		}
+	}
+}
+
+// nestedStruct returns the struct val holds, if any. time.Time is treated
+// as a value rather than a struct to descend into.
+func nestedStruct(val reflect.Value) (reflect.Value, bool) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return reflect.Value{}, false
+		}
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.Struct || val.Type() == reflect.TypeOf(time.Time{}) {
+		return reflect.Value{}, false
+	}
+	return val, true
+}
+
+// addError adds a validation error, using the registered message template
+// for the field and rule when there is one
+func (v *validation) addError(field, rule, message string) {
+	if v.done() {
+		return
+	}
+
+	template, registered := v.message(field, rule)
+	if registered {
+		message = formatMessage(template, field, v.param)
+	}
+
 	v.errors = append(v.errors, ValidationError{
-		Field:   field,
-		Rule:    rule,
-		Message: message,
+		Field:      field,
+		Rule:       rule,
+		Param:      v.param,
+		Message:    message,
+		registered: registered,
 	})
 }
 
-// validateField validates a single field against a rule
-func (v *Validator) validateField(fieldName string, value interface{}, rule string) {
-	parts := strings.Split(rule, "=")
-	ruleName := parts[0]
-	ruleValue := ""
-	if len(parts) > 1 {
-		ruleValue = parts[1]
+func (v *Validator) message(field, rule string) (string, bool) {
+	if template, ok := v.fieldMessages[indexPattern.ReplaceAllString(field, "")][rule]; ok {
+		return template, true
 	}
+	template, ok := v.messages[rule]
+	return template, ok
+}
+
+func formatMessage(template, field, param string) string {
+	return strings.NewReplacer("{field}", field, "{param}", param).Replace(template)
+}
+
+// indexPattern matches the slice and map indexes of a field path
+var indexPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+// validateField validates a single field against a rule
+func (v *validation) validateField(fieldName string, value interface{}, r rule) {
+	ruleName, ruleValue := r.name, r.param
+	v.param = ruleValue
 
 	switch ruleName {
 	// Basic validations
+	case "omitempty":
+		// Applied by validateValue
+	case "default":
+		// Applied by ValidateVars
 	case "required":
-		if !v.required(value) {
+		if !v.present(value) {
 			v.addError(fieldName, ruleName, "field is required")
 		}
+	case "required_if":
+		v.validateRequiredIf(fieldName, value, ruleName, ruleValue, true)
+	case "required_unless":
+		v.validateRequiredIf(fieldName, value, ruleName, ruleValue, false)
+	case "required_with":
+		v.validateRequiredWith(fieldName, value, ruleValue)
+	case "password":
+		v.validatePassword(fieldName, value, ruleValue)
 	case "notnil":
 		if value == nil {
 			v.addError(fieldName, ruleName, "field cannot be nil")
@@ -149,6 +673,10 @@ func (v *Validator) validateField(fieldName string, value interface{}, rule stri
 		if !v.alphanum(str) {
 			v.addError(fieldName, ruleName, "field must contain only letters and numbers")
 		}
+	case "int":
+		if !v.integer(value) {
+			v.addError(fieldName, ruleName, "field must be an integer")
+		}
 	case "numeric":
 		str, ok := value.(string)
 		if !ok {
@@ -167,6 +695,8 @@ func (v *Validator) validateField(fieldName string, value interface{}, rule stri
 		if !v.lowercase(str) {
 			v.addError(fieldName, ruleName, "field must be lowercase")
 		}
+	case "slug":
+		v.validateString(fieldName, ruleName, value, v.slug, "field must be a lowercase slug")
 	case "uppercase":
 		str, ok := value.(string)
 		if !ok {
@@ -177,6 +707,34 @@ func (v *Validator) validateField(fieldName string, value interface{}, rule stri
 			v.addError(fieldName, ruleName, "field must be uppercase")
 		}
 
+	// Network and identifier formats
+	case "uuid":
+		v.validateString(fieldName, ruleName, value, v.uuid, "invalid UUID format")
+	case "uuid4":
+		v.validateString(fieldName, ruleName, value, v.uuid4, "invalid UUID v4 format")
+	case "ipv4":
+		v.validateString(fieldName, ruleName, value, v.ipv4, "invalid IPv4 address")
+	case "ipv6":
+		v.validateString(fieldName, ruleName, value, v.ipv6, "invalid IPv6 address")
+	case "cidr":
+		v.validateString(fieldName, ruleName, value, v.cidr, "invalid CIDR notation")
+	case "mac":
+		v.validateString(fieldName, ruleName, value, v.mac, "invalid MAC address")
+	case "hostname":
+		v.validateString(fieldName, ruleName, value, v.hostname, "invalid hostname")
+	case "phone":
+		v.validatePhone(fieldName, value, ruleValue)
+	case "port":
+		if !v.port(value) {
+			v.addError(fieldName, ruleName, "port must be between 1 and 65535")
+		}
+
+	// Localization
+	case "timezone":
+		v.validateString(fieldName, ruleName, value, v.timezone, "invalid timezone")
+	case "locale":
+		v.validateString(fieldName, ruleName, value, v.locale, "unsupported locale")
+
 	// Length validations
 	case "min":
 		v.validateMin(fieldName, value, ruleValue)
@@ -184,6 +742,12 @@ func (v *Validator) validateField(fieldName string, value interface{}, rule stri
 		v.validateMax(fieldName, value, ruleValue)
 	case "len":
 		v.validateLen(fieldName, value, ruleValue)
+	case "maxbytes":
+		v.validateMaxBytes(fieldName, value, ruleValue)
+
+	// Enum validation
+	case "oneof":
+		v.validateOneOf(fieldName, value, ruleValue)
 
 	// Number range validations
 	case "range":
@@ -229,6 +793,17 @@ func (v *Validator) validateField(fieldName string, value interface{}, rule stri
 			v.addError(fieldName, ruleName, "time must be in the past")
 		}
 
+	case "iso8601":
+		if _, ok := dateValue(value); !ok {
+			v.addError(fieldName, ruleName, "invalid ISO 8601 date")
+		}
+	case "after", "before", "after_field", "before_field":
+		v.validateDateOrder(fieldName, ruleName, value, ruleValue)
+
+	// Database validations
+	case "unique_db":
+		v.validateUniqueDB(fieldName, value, ruleValue)
+
 	// Slice validations
 	case "unique":
 		if !v.unique(value) {
@@ -245,11 +820,23 @@ func (v *Validator) validateField(fieldName string, value interface{}, rule stri
 	}
 }
 
+// present reports whether a value satisfies the required rules. Request
+// variables are present unless empty; struct fields unless zero.
+func (v *validation) present(value interface{}) bool {
+	if v.vars != nil {
+		return value != nil
+	}
+	return v.required(value)
+}
+
 // Validation implementations
 func (v *Validator) required(value interface{}) bool {
 	if value == nil {
 		return false
 	}
+	if t, ok := value.(time.Time); ok {
+		return !t.IsZero()
+	}
 
 	val := reflect.ValueOf(value)
 	switch val.Kind() {
@@ -271,6 +858,280 @@ func (v *Validator) required(value interface{}) bool {
 	return true
 }
 
+// validateRequiredIf checks the required_if and required_unless rules. The
+// rule value lists "Field value" pairs; the field is required when every
+// sibling field equals its value (required_if) or when any doesn't
+// (required_unless).
+func (v *validation) validateRequiredIf(fieldName string, value interface{}, ruleName string, params string, when bool) {
+	pairs := strings.Fields(params)
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		v.addError(fieldName, ruleName, "invalid "+ruleName+" parameters")
+		return
+	}
+
+	matches := true
+	for i := 0; i < len(pairs); i += 2 {
+		other, ok := v.sibling(pairs[i])
+		if !ok {
+			v.addError(fieldName, ruleName, fmt.Sprintf("unknown field %s", pairs[i]))
+			return
+		}
+		if fmt.Sprint(other) != pairs[i+1] {
+			matches = false
+			break
+		}
+	}
+
+	if matches == when && !v.present(value) {
+		v.addError(fieldName, ruleName, "field is required")
+	}
+}
+
+// validateRequiredWith requires the field when any of the space-separated
+// sibling fields is present
+func (v *validation) validateRequiredWith(fieldName string, value interface{}, params string) {
+	for _, name := range strings.Fields(params) {
+		other, ok := v.sibling(name)
+		if !ok {
+			v.addError(fieldName, "required_with", fmt.Sprintf("unknown field %s", name))
+			return
+		}
+		if v.present(other) {
+			if !v.present(value) {
+				v.addError(fieldName, "required_with", "field is required")
+			}
+			return
+		}
+	}
+}
+
+// sibling returns the value of the named field of the struct being
+// validated, or the named variable for ValidateVars, as rules see it. Nil
+// pointers and null values are returned as nil.
+func (v *validation) sibling(name string) (interface{}, bool) {
+	if v.vars != nil {
+		value, ok := v.vars[name]
+		return value, ok
+	}
+	if !v.parent.IsValid() {
+		return nil, false
+	}
+	field := v.parent.FieldByName(name)
+	if !field.IsValid() || !field.CanInterface() {
+		return nil, false
+	}
+	return fieldValue(field), true
+}
+
+// validateString checks a string field with check, adding message when it
+// fails
+func (v *validation) validateString(fieldName, ruleName string, value interface{}, check func(string) bool, message string) {
+	str, ok := value.(string)
+	if !ok {
+		v.addError(fieldName, ruleName, "field must be a string")
+		return
+	}
+	if !check(str) {
+		v.addError(fieldName, ruleName, message)
+	}
+}
+
+var (
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	uuid4Pattern    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-4[0-9a-fA-F]{3}-[89abAB][0-9a-fA-F]{3}-[0-9a-fA-F]{12}$`)
+	hostnamePattern = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.?$`)
+)
+
+func (v *Validator) uuid(value string) bool {
+	return uuidPattern.MatchString(value)
+}
+
+func (v *Validator) uuid4(value string) bool {
+	return uuid4Pattern.MatchString(value)
+}
+
+func (v *Validator) ipv4(value string) bool {
+	ip := net.ParseIP(value)
+	return ip != nil && !strings.Contains(value, ":")
+}
+
+func (v *Validator) ipv6(value string) bool {
+	ip := net.ParseIP(value)
+	return ip != nil && strings.Contains(value, ":")
+}
+
+func (v *Validator) cidr(value string) bool {
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
+
+func (v *Validator) mac(value string) bool {
+	_, err := net.ParseMAC(value)
+	return err == nil
+}
+
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// callingCodes maps ISO 3166 region codes to their country calling code
+var callingCodes = map[string]string{
+	"US": "1", "CA": "1", "MX": "52", "BR": "55", "AR": "54", "CO": "57",
+	"GB": "44", "IE": "353", "FR": "33", "DE": "49", "ES": "34", "PT": "351",
+	"IT": "39", "NL": "31", "BE": "32", "CH": "41", "AT": "43", "SE": "46",
+	"NO": "47", "DK": "45", "FI": "358", "PL": "48", "RU": "7", "TR": "90",
+	"MA": "212", "DZ": "213", "TN": "216", "EG": "20", "NG": "234", "ZA": "27",
+	"AE": "971", "SA": "966", "IN": "91", "CN": "86", "JP": "81", "KR": "82",
+	"SG": "65", "ID": "62", "AU": "61", "NZ": "64",
+}
+
+// validatePhone checks an E.164 phone number such as "+33612345678". With
+// a region, as in phone=FR, the number must also use the region's calling
+// code.
+func (v *validation) validatePhone(fieldName string, value interface{}, region string) {
+	str, ok := value.(string)
+	if !ok {
+		v.addError(fieldName, "phone", "field must be a string")
+		return
+	}
+	if str == "" {
+		return
+	}
+
+	if !phonePattern.MatchString(str) {
+		v.addError(fieldName, "phone", "invalid phone number")
+		return
+	}
+
+	if region == "" {
+		return
+	}
+	code, ok := callingCodes[strings.ToUpper(region)]
+	if !ok {
+		v.addError(fieldName, "phone", "invalid phone region")
+		return
+	}
+	if !strings.HasPrefix(str, "+"+code) {
+		v.addError(fieldName, "phone", fmt.Sprintf("phone number must be a %s number", strings.ToUpper(region)))
+	}
+}
+
+// hostname checks an RFC 1123 host name of at most 253 characters
+func (v *Validator) hostname(value string) bool {
+	return len(strings.TrimSuffix(value, ".")) <= 253 && hostnamePattern.MatchString(value)
+}
+
+// port accepts a port number as an integer or a numeric string
+func (v *Validator) port(value interface{}) bool {
+	val := reflect.ValueOf(value)
+	var port int64
+	switch val.Kind() {
+	case reflect.String:
+		n, err := strconv.ParseInt(val.String(), 10, 64)
+		if err != nil {
+			return false
+		}
+		port = n
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		port = val.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if val.Uint() > 65535 {
+			return false
+		}
+		port = int64(val.Uint())
+	default:
+		return false
+	}
+	return port >= 1 && port <= 65535
+}
+
+// validatePassword checks a password against the password policy. The
+// rule value, as in password=12, overrides the policy minimum length.
+// Empty passwords are left to the required rule.
+func (v *validation) validatePassword(fieldName string, value interface{}, minStr string) {
+	password, ok := value.(string)
+	if !ok {
+		v.addError(fieldName, "password", "field must be a string")
+		return
+	}
+	if password == "" {
+		return
+	}
+
+	policy := v.passwordPolicy
+	if minStr != "" {
+		min, err := strconv.Atoi(minStr)
+		if err != nil {
+			v.addError(fieldName, "password", "invalid password value")
+			return
+		}
+		policy.MinLength = min
+	}
+
+	var hasUpper, hasLower, hasNumber, hasSpecial bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsNumber(char):
+			hasNumber = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSpecial = true
+		}
+	}
+
+	var missing []string
+	if policy.RequireUpper && !hasUpper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if policy.RequireLower && !hasLower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if policy.RequireNumber && !hasNumber {
+		missing = append(missing, "a number")
+	}
+	if policy.RequireSpecial && !hasSpecial {
+		missing = append(missing, "a special character")
+	}
+
+	tooShort := utf8.RuneCountInString(password) < policy.MinLength
+	switch {
+	case tooShort && len(missing) > 0:
+		v.addError(fieldName, "password", fmt.Sprintf("password must be at least %d characters long and contain %s",
+			policy.MinLength, strings.Join(missing, ", ")))
+	case tooShort:
+		v.addError(fieldName, "password", fmt.Sprintf("password must be at least %d characters long", policy.MinLength))
+	case len(missing) > 0:
+		v.addError(fieldName, "password", "password must contain "+strings.Join(missing, ", "))
+	}
+}
+
+// timezone accepts IANA time zone names such as "Europe/Paris". Empty
+// values are left to the required rule.
+func (v *Validator) timezone(value string) bool {
+	if value == "" {
+		return true
+	}
+	if value == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(value)
+	return err == nil
+}
+
+// locale accepts the locales of DefaultCatalog. Empty values are left to
+// the required rule.
+func (v *Validator) locale(value string) bool {
+	return value == "" || DefaultCatalog.Has(value)
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// slug accepts lowercase words of letters and digits joined by dashes
+func (v *Validator) slug(value string) bool {
+	return slugPattern.MatchString(value)
+}
+
 func (v *Validator) email(value string) bool {
 	pattern := `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
 	match, _ := regexp.MatchString(pattern, value)
@@ -300,6 +1161,20 @@ func (v *Validator) alphanum(value string) bool {
 	return true
 }
 
+// integer reports whether value is an integer or a string holding one
+func (v *Validator) integer(value interface{}) bool {
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.String:
+		_, err := strconv.ParseInt(val.String(), 10, 64)
+		return err == nil
+	}
+	return false
+}
+
 func (v *Validator) numeric(value string) bool {
 	for _, r := range value {
 		if !unicode.IsNumber(r) {
@@ -317,7 +1192,7 @@ func (v *Validator) uppercase(value string) bool {
 	return strings.ToUpper(value) == value
 }
 
-func (v *Validator) validateMin(fieldName string, value interface{}, minStr string) {
+func (v *validation) validateMin(fieldName string, value interface{}, minStr string) {
 	min, err := strconv.Atoi(minStr)
 	if err != nil {
 		v.addError(fieldName, "min", "invalid min value")
@@ -327,7 +1202,7 @@ func (v *Validator) validateMin(fieldName string, value interface{}, minStr stri
 	val := reflect.ValueOf(value)
 	switch val.Kind() {
 	case reflect.String:
-		if len(val.String()) < min {
+		if v.length(val.String()) < min {
 			v.addError(fieldName, "min", fmt.Sprintf("length must be at least %d", min))
 		}
 	case reflect.Slice, reflect.Map, reflect.Array:
@@ -345,7 +1220,7 @@ func (v *Validator) validateMin(fieldName string, value interface{}, minStr stri
 	}
 }
 
-func (v *Validator) validateMax(fieldName string, value interface{}, maxStr string) {
+func (v *validation) validateMax(fieldName string, value interface{}, maxStr string) {
 	max, err := strconv.Atoi(maxStr)
 	if err != nil {
 		v.addError(fieldName, "max", "invalid max value")
@@ -355,7 +1230,7 @@ func (v *Validator) validateMax(fieldName string, value interface{}, maxStr stri
 	val := reflect.ValueOf(value)
 	switch val.Kind() {
 	case reflect.String:
-		if len(val.String()) > max {
+		if v.length(val.String()) > max {
 			v.addError(fieldName, "max", fmt.Sprintf("length must not exceed %d", max))
 		}
 	case reflect.Slice, reflect.Map, reflect.Array:
@@ -373,7 +1248,7 @@ func (v *Validator) validateMax(fieldName string, value interface{}, maxStr stri
 	}
 }
 
-func (v *Validator) validateLen(fieldName string, value interface{}, lenStr string) {
+func (v *validation) validateLen(fieldName string, value interface{}, lenStr string) {
 	length, err := strconv.Atoi(lenStr)
 	if err != nil {
 		v.addError(fieldName, "len", "invalid length value")
@@ -383,7 +1258,7 @@ func (v *Validator) validateLen(fieldName string, value interface{}, lenStr stri
 	val := reflect.ValueOf(value)
 	switch val.Kind() {
 	case reflect.String:
-		if len(val.String()) != length {
+		if v.length(val.String()) != length {
 			v.addError(fieldName, "len", fmt.Sprintf("length must be exactly %d", length))
 		}
 	case reflect.Slice, reflect.Map, reflect.Array:
@@ -393,7 +1268,68 @@ func (v *Validator) validateLen(fieldName string, value interface{}, lenStr stri
 	}
 }
 
-func (v *Validator) validateRange(fieldName string, value interface{}, rangeStr string) {
+// validateMaxBytes bounds the byte length of a string, for column limits
+// expressed in bytes
+func (v *validation) validateMaxBytes(fieldName string, value interface{}, maxStr string) {
+	max, err := strconv.Atoi(maxStr)
+	if err != nil {
+		v.addError(fieldName, "maxbytes", "invalid maxbytes value")
+		return
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		v.addError(fieldName, "maxbytes", "field must be a string")
+		return
+	}
+	if len(str) > max {
+		v.addError(fieldName, "maxbytes", fmt.Sprintf("size must not exceed %d bytes", max))
+	}
+}
+
+// length returns the length of a string in runes, or in grapheme clusters
+// with WithGraphemeLength
+func (v *Validator) length(s string) int {
+	if v.graphemes {
+		return graphemeCount(s)
+	}
+	return utf8.RuneCountInString(s)
+}
+
+// graphemeCount approximates the number of extended grapheme clusters:
+// combining marks, variation selectors, emoji modifiers and characters
+// joined by a zero width joiner extend the previous character, and
+// regional indicators pair up into flags
+func graphemeCount(s string) int {
+	count := 0
+	joined := false
+	regional := false
+	for _, r := range s {
+		switch {
+		case r == '\u200d':
+			joined = true
+			continue
+		case joined:
+			joined = false
+			continue
+		case unicode.In(r, unicode.Mn, unicode.Me),
+			r >= 0xfe00 && r <= 0xfe0f,
+			r >= 0x1f3fb && r <= 0x1f3ff:
+			continue
+		case r >= 0x1f1e6 && r <= 0x1f1ff:
+			regional = !regional
+			if !regional {
+				continue
+			}
+		default:
+			regional = false
+		}
+		count++
+	}
+	return count
+}
+
+func (v *validation) validateRange(fieldName string, value interface{}, rangeStr string) {
 	parts := strings.Split(rangeStr, "-")
 	if len(parts) != 2 {
 		v.addError(fieldName, "range", "invalid range format")
@@ -422,6 +1358,35 @@ func (v *Validator) validateRange(fieldName string, value interface{}, rangeStr
 	}
 }
 
+// validateOneOf checks that a string or number equals one of the space- or
+// pipe-separated allowed values
+func (v *validation) validateOneOf(fieldName string, value interface{}, allowedStr string) {
+	allowed := strings.FieldsFunc(allowedStr, func(r rune) bool { return r == ' ' || r == '|' })
+	if len(allowed) == 0 {
+		v.addError(fieldName, "oneof", "invalid oneof values")
+		return
+	}
+
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+	default:
+		v.addError(fieldName, "oneof", "field must be a string or number")
+		return
+	}
+
+	str := fmt.Sprint(val.Interface())
+	for _, a := range allowed {
+		if str == a {
+			return
+		}
+	}
+	v.addError(fieldName, "oneof", fmt.Sprintf("field must be one of: %s", strings.Join(allowed, ", ")))
+}
+
 func (v *Validator) pattern(value string, pattern string) bool {
 	match, err := regexp.MatchString(pattern, value)
 	return err == nil && match
@@ -443,6 +1408,110 @@ func (v *Validator) past(t time.Time) bool {
 	return t.Before(time.Now())
 }
 
+// iso8601Layouts are the ISO 8601 forms dates are accepted in. Values
+// without a zone are taken as UTC.
+var iso8601Layouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// dateValue returns the time of a time.Time or an ISO 8601 string
+func dateValue(value interface{}) (time.Time, bool) {
+	switch value := value.(type) {
+	case time.Time:
+		return value, true
+	case string:
+		for _, layout := range iso8601Layouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// dateBound returns the time of an after or before parameter: "now",
+// "today" (midnight UTC) or an ISO 8601 date
+func dateBound(param string) (time.Time, bool) {
+	switch param {
+	case "now":
+		return time.Now(), true
+	case "today":
+		return time.Now().UTC().Truncate(24 * time.Hour), true
+	}
+	return dateValue(param)
+}
+
+// validateDateOrder checks that a date is strictly after or before the
+// bound of the rule, which for after_field and before_field is the value
+// of the named sibling field. Malformed date strings are left to the
+// iso8601 rule, and the check is skipped while the sibling field is absent
+// or not a date, which its own rules report.
+func (v *validation) validateDateOrder(fieldName, ruleName string, value interface{}, param string) {
+	t, ok := dateValue(value)
+	if !ok {
+		if _, isString := value.(string); !isString {
+			v.addError(fieldName, ruleName, "field must be a time or an ISO 8601 date")
+		}
+		return
+	}
+
+	var bound time.Time
+	if strings.HasSuffix(ruleName, "_field") {
+		other, found := v.sibling(param)
+		if !found {
+			v.addError(fieldName, ruleName, fmt.Sprintf("unknown field %s", param))
+			return
+		}
+		if isZero(other) {
+			return
+		}
+		if bound, ok = dateValue(other); !ok {
+			return
+		}
+	} else if bound, ok = dateBound(param); !ok {
+		v.addError(fieldName, ruleName, "invalid "+ruleName+" value")
+		return
+	}
+
+	if strings.HasPrefix(ruleName, "after") {
+		if !t.After(bound) {
+			v.addError(fieldName, ruleName, "date must be after "+param)
+		}
+	} else if !t.Before(bound) {
+		v.addError(fieldName, ruleName, "date must be before "+param)
+	}
+}
+
+// validateUniqueDB checks with the UniqueChecker that no row has the
+// value in the column of a "table.column" parameter
+func (v *validation) validateUniqueDB(fieldName string, value interface{}, param string) {
+	table, column, ok := strings.Cut(param, ".")
+	if !ok || table == "" || column == "" {
+		v.addError(fieldName, "unique_db", "invalid unique_db parameters")
+		return
+	}
+	if v.uniqueChecker == nil {
+		v.err = errors.New("validator: unique_db rule needs a UniqueChecker")
+		return
+	}
+
+	ctx := v.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	unique, err := v.uniqueChecker.Unique(ctx, table, column, value)
+	if err != nil {
+		v.err = fmt.Errorf("validator: checking %s is unique: %w", fieldName, err)
+		return
+	}
+	if !unique {
+		v.addError(fieldName, "unique_db", "value already taken")
+	}
+}
+
 func (v *Validator) unique(value interface{}) bool {
 	val := reflect.ValueOf(value)
 	if val.Kind() != reflect.Slice {
@@ -0,0 +1,497 @@
+package validator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// failures validates s and returns its failures as "field:rule"
+func failures(t *testing.T, v *Validator, s interface{}) []string {
+	t.Helper()
+
+	err := v.Validate(s)
+	if err == nil {
+		return nil
+	}
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate = %v, want ValidationErrors", err)
+	}
+
+	got := make([]string, len(errs))
+	for i, e := range errs {
+		got[i] = e.Field + ":" + e.Rule
+	}
+	return got
+}
+
+type memberPayload struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type projectPayload struct {
+	Owner   memberPayload     `json:"owner"`
+	Lead    *memberPayload    `json:"lead"`
+	Members []memberPayload   `json:"members" validate:"required,dive"`
+	Emails  []string          `json:"emails" validate:"dive,email"`
+	Labels  map[string]string `json:"labels" validate:"dive,required"`
+}
+
+func TestValidateNested(t *testing.T) {
+	valid := func() projectPayload {
+		return projectPayload{
+			Owner:   memberPayload{Email: "owner@example.com"},
+			Members: []memberPayload{{Email: "a@example.com"}},
+			Emails:  []string{"a@example.com"},
+			Labels:  map[string]string{"team": "core"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(p *projectPayload)
+		want   []string
+	}{
+		{"valid", func(p *projectPayload) {}, nil},
+		{"nested struct", func(p *projectPayload) { p.Owner.Email = "" }, []string{"owner.email:required", "owner.email:email"}},
+		{"nested pointer", func(p *projectPayload) { p.Lead = &memberPayload{Email: "lead"} }, []string{"lead.email:email"}},
+		{"nil nested pointer", func(p *projectPayload) { p.Lead = nil }, nil},
+		{"empty slice", func(p *projectPayload) { p.Members = nil }, []string{"members:required"}},
+		{"slice of structs", func(p *projectPayload) {
+			p.Members = append(p.Members, memberPayload{Email: "b"})
+		}, []string{"members[1].email:email"}},
+		{"indexed path", func(p *projectPayload) {
+			p.Emails = []string{"a@example.com", "b@example.com", "c"}
+		}, []string{"emails[2]:email"}},
+		{"map values", func(p *projectPayload) { p.Labels["owner"] = "" }, []string{"labels[owner]:required"}},
+	}
+
+	v := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid()
+			tt.modify(&p)
+			if got := failures(t, v, p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failures = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type shippingPayload struct {
+	Method  string `json:"method"`
+	Address string `json:"address" validate:"required_if=Method delivery"`
+	Store   string `json:"store" validate:"required_unless=Method delivery"`
+	Phone   string `json:"phone"`
+	Country string `json:"country" validate:"required_with=Phone"`
+}
+
+func TestValidateConditionalRequired(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload shippingPayload
+		want    []string
+	}{
+		{"delivery with address", shippingPayload{Method: "delivery", Address: "1 Main St"}, nil},
+		{"delivery without address", shippingPayload{Method: "delivery"}, []string{"address:required_if"}},
+		{"pickup with store", shippingPayload{Method: "pickup", Store: "Paris"}, nil},
+		{"pickup without store", shippingPayload{Method: "pickup"}, []string{"store:required_unless"}},
+		{"phone with country", shippingPayload{Method: "pickup", Store: "Paris", Phone: "+33612345678", Country: "FR"}, nil},
+		{"phone without country", shippingPayload{Method: "pickup", Store: "Paris", Phone: "+33612345678"}, []string{"country:required_with"}},
+	}
+
+	v := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failures(t, v, tt.payload); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failures = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// ruleCase is a value checked against the rules of a tag
+type ruleCase struct {
+	tag   string
+	value interface{}
+	valid bool
+}
+
+// checkRuleCases checks each value against its tag as a field would be
+func checkRuleCases(t *testing.T, v *Validator, cases []ruleCase) {
+	t.Helper()
+
+	for _, c := range cases {
+		run := &validation{Validator: v}
+		run.checkRules("field", c.value, parseRules(c.tag))
+		if valid := len(run.errors) == 0; valid != c.valid {
+			t.Errorf("%s on %#v: valid = %v, want %v (errors %v)", c.tag, c.value, valid, c.valid, run.errors)
+		}
+	}
+}
+
+func TestOneOf(t *testing.T) {
+	checkRuleCases(t, New(), []ruleCase{
+		{"oneof=todo doing done", "doing", true},
+		{"oneof=todo|doing|done", "done", true},
+		{"oneof=todo doing done", "Done", false},
+		{"oneof=todo doing done", "", false},
+		{"oneof=1 2 3", 2, true},
+		{"oneof=1 2 3", int64(4), false},
+		{"oneof=0.5 1.5", 1.5, true},
+		{"oneof=a b", []string{"a"}, false},
+		{"oneof=", "a", false},
+	})
+}
+
+func TestNetworkFormats(t *testing.T) {
+	checkRuleCases(t, New(), []ruleCase{
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", true},
+		{"uuid", "123e4567e89b12d3a456426614174000", false},
+		{"uuid4", "9b2f1c1e-8d3a-4f6b-9c2d-1e2f3a4b5c6d", true},
+		{"uuid4", "123e4567-e89b-12d3-a456-426614174000", false},
+		{"ipv4", "192.168.1.10", true},
+		{"ipv4", "256.1.1.1", false},
+		{"ipv4", "::1", false},
+		{"ipv6", "2001:db8::1", true},
+		{"ipv6", "192.168.1.10", false},
+		{"cidr", "10.0.0.0/8", true},
+		{"cidr", "2001:db8::/32", true},
+		{"cidr", "10.0.0.0", false},
+		{"mac", "00:1a:2b:3c:4d:5e", true},
+		{"mac", "00:1a:2b", false},
+		{"hostname", "api.example.com", true},
+		{"hostname", "localhost", true},
+		{"hostname", "-bad.example.com", false},
+		{"hostname", "under_score.example.com", false},
+		{"port", 443, true},
+		{"port", "8080", true},
+		{"port", uint16(65535), true},
+		{"port", 0, false},
+		{"port", 65536, false},
+		{"port", "http", false},
+		{"uuid", 42, false},
+	})
+}
+
+func TestPassword(t *testing.T) {
+	checkRuleCases(t, New(), []ruleCase{
+		{"password", "Str0ng!pass", true},
+		{"password", "Sh0rt!", false},
+		{"password", "str0ng!pass", false},
+		{"password", "STR0NG!PASS", false},
+		{"password", "Strong!pass", false},
+		{"password", "Str0ngpass", false},
+		{"password", "Çà0ñ!été", true},
+		{"password=12", "Str0ng!pass", false},
+		{"password=12", "Str0ng!passw", true},
+		{"password=x", "Str0ng!pass", false},
+		{"password", "", true},
+		{"password", 12345678, false},
+	})
+
+	v := New()
+	v.SetPasswordPolicy(PasswordPolicy{MinLength: 4, RequireNumber: true})
+	checkRuleCases(t, v, []ruleCase{
+		{"password", "abc1", true},
+		{"password", "abcd", false},
+		{"password", "ab1", false},
+	})
+}
+
+func TestPasswordMessage(t *testing.T) {
+	run := &validation{Validator: New()}
+	run.checkRules("password", "abc", parseRules("password"))
+
+	want := "password must be at least 8 characters long and contain an uppercase letter, a number, a special character"
+	if len(run.errors) != 1 || run.errors[0].Message != want {
+		t.Errorf("errors = %v, want one with message %q", run.errors, want)
+	}
+}
+
+func TestPhone(t *testing.T) {
+	checkRuleCases(t, New(), []ruleCase{
+		{"phone", "+33612345678", true},
+		{"phone", "+14155552671", true},
+		{"phone", "0612345678", false},
+		{"phone", "+33 6 12 34 56 78", false},
+		{"phone", "+0612345678", false},
+		{"phone", "+1234567890123456", false},
+		{"phone", "", true},
+		{"phone=FR", "+33612345678", true},
+		{"phone=fr", "+33612345678", true},
+		{"phone=FR", "+14155552671", false},
+		{"phone=US", "+14155552671", true},
+		{"phone=XX", "+33612345678", false},
+		{"phone", 33612345678, false},
+	})
+}
+
+type signupPayload struct {
+	Name   string   `json:"name" validate:"required,min=2"`
+	Email  string   `json:"email" validate:"required,email"`
+	Emails []string `json:"emails" validate:"dive,email"`
+}
+
+func TestErrorLimits(t *testing.T) {
+	payload := signupPayload{Emails: []string{"a", "b", "c"}}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{"no limit", nil, []string{
+			"name:required", "name:min", "email:required", "email:email",
+			"emails[0]:email", "emails[1]:email", "emails[2]:email",
+		}},
+		{"fail fast", []Option{WithFailFast()}, []string{"name:required"}},
+		{"max errors", []Option{WithMaxErrors(3)}, []string{"name:required", "name:min", "email:required"}},
+		{"max errors in dive", []Option{WithMaxErrors(6)}, []string{
+			"name:required", "name:min", "email:required", "email:email",
+			"emails[0]:email", "emails[1]:email",
+		}},
+		{"zero max errors", []Option{WithMaxErrors(0)}, []string{
+			"name:required", "name:min", "email:required", "email:email",
+			"emails[0]:email", "emails[1]:email", "emails[2]:email",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failures(t, New(tt.opts...), payload); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failures = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type profilePayload struct {
+	Website string         `json:"website" validate:"omitempty,url"`
+	Age     int            `json:"age" validate:"omitempty,min=13"`
+	Bio     *string        `json:"bio" validate:"omitempty,min=10"`
+	Lead    *memberPayload `json:"lead" validate:"omitempty"`
+	Tags    []string       `json:"tags" validate:"omitempty,dive,min=2"`
+}
+
+func TestOmitEmpty(t *testing.T) {
+	short, empty := "short", ""
+
+	tests := []struct {
+		name    string
+		payload profilePayload
+		want    []string
+	}{
+		{"zero values", profilePayload{}, nil},
+		{"pointer to empty string", profilePayload{Bio: &empty}, nil},
+		{"invalid string", profilePayload{Website: "not a url"}, []string{"website:url"}},
+		{"invalid number", profilePayload{Age: 12}, []string{"age:min"}},
+		{"invalid pointer", profilePayload{Bio: &short}, []string{"bio:min"}},
+		{"invalid nested struct", profilePayload{Lead: &memberPayload{Email: "lead"}}, []string{"lead.email:email"}},
+		{"invalid element", profilePayload{Tags: []string{"go", "x"}}, []string{"tags[1]:min"}},
+	}
+
+	v := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failures(t, v, tt.payload); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failures = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringLengths(t *testing.T) {
+	const (
+		accented = "h\u00e9llo"                                 // 5 runes, 6 bytes
+		japanese = "\u3053\u3093\u306b\u3061\u306f"             // 5 runes, 15 bytes
+		family   = "\U0001f468\u200d\U0001f469\u200d\U0001f467" // 5 runes, 1 grapheme
+		flag     = "\U0001f1eb\U0001f1f7"                       // 2 runes, 1 grapheme
+		thumbs   = "\U0001f44d\U0001f3fd"                       // 2 runes, 1 grapheme
+		combined = "e\u0301"                                    // 2 runes, 1 grapheme
+	)
+
+	checkRuleCases(t, New(), []ruleCase{
+		{"len=5", accented, true},
+		{"len=5", japanese, true},
+		{"max=4", japanese, false},
+		{"min=5", family, true},
+		{"len=2", flag, true},
+		{"len=2", combined, true},
+		{"maxbytes=6", accented, true},
+		{"maxbytes=5", accented, false},
+		{"maxbytes=14", japanese, false},
+		{"maxbytes=4", flag, false},
+		{"maxbytes=x", "a", false},
+		{"maxbytes=4", 42, false},
+	})
+
+	checkRuleCases(t, New(WithGraphemeLength()), []ruleCase{
+		{"len=5", accented, true},
+		{"len=1", family, true},
+		{"max=1", family, true},
+		{"len=1", flag, true},
+		{"len=2", flag + flag, true},
+		{"len=1", thumbs, true},
+		{"len=1", combined, true},
+		{"min=2", thumbs, false},
+		{"maxbytes=4", flag, false},
+	})
+}
+
+type settingsPayload struct {
+	Labels map[string]string `json:"labels" validate:"dive,keys,slug,endkeys,required"`
+	Limits map[string]int    `json:"limits" validate:"dive,keys,oneof=tasks members,endkeys,min=1"`
+}
+
+func TestValidateMapKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload settingsPayload
+		want    []string
+	}{
+		{"valid", settingsPayload{
+			Labels: map[string]string{"team-a": "core"},
+			Limits: map[string]int{"tasks": 10},
+		}, nil},
+		{"invalid keys", settingsPayload{
+			Labels: map[string]string{"Team A": "core", "ok": "x", "bad_key": "y"},
+		}, []string{"labels[Team A]:slug", "labels[bad_key]:slug"}},
+		{"invalid key and value", settingsPayload{
+			Limits: map[string]int{"projects": 0},
+		}, []string{"limits[projects]:oneof", "limits[projects]:min"}},
+	}
+
+	v := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failures(t, v, tt.payload); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failures = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMapKeysWithoutEndKeys(t *testing.T) {
+	payload := struct {
+		Labels map[string]string `json:"labels" validate:"dive,keys,slug"`
+	}{Labels: map[string]string{"a": "b"}}
+
+	if got, want := failures(t, New(), payload), []string{"labels:keys"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("failures = %v, want %v", got, want)
+	}
+}
+
+func TestValidateVars(t *testing.T) {
+	rules := map[string]string{
+		"id":     "required,int,min=1",
+		"page":   "omitempty,int,min=1,default=1",
+		"status": "omitempty,oneof=open closed",
+		"sort":   "default=created_at,oneof=created_at name",
+	}
+
+	tests := []struct {
+		name   string
+		vars   map[string]string
+		want   map[string]string
+		failed []string
+	}{
+		{"defaults", map[string]string{"id": "7"},
+			map[string]string{"id": "7", "page": "1", "sort": "created_at"}, nil},
+		{"provided values", map[string]string{"id": "7", "page": "3", "status": "open", "sort": "name"},
+			map[string]string{"id": "7", "page": "3", "status": "open", "sort": "name"}, nil},
+		{"empty is absent", map[string]string{"id": "7", "page": ""},
+			map[string]string{"id": "7", "page": "1", "sort": "created_at"}, nil},
+		{"zero is present", map[string]string{"id": "0"}, nil, []string{"id:min"}},
+		{"missing required", map[string]string{}, nil, []string{"id:required"}},
+		{"not an int", map[string]string{"id": "abc"}, nil, []string{"id:int"}},
+		{"invalid values", map[string]string{"id": "7", "page": "0", "status": "draft"}, nil,
+			[]string{"page:min", "status:oneof"}},
+	}
+
+	v := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := make(map[string]string, len(tt.vars))
+			for name, value := range tt.vars {
+				vars[name] = value
+			}
+
+			got, err := v.ValidateVars(vars, rules)
+			if !reflect.DeepEqual(vars, tt.vars) {
+				t.Errorf("vars = %v, want them unchanged %v", vars, tt.vars)
+			}
+
+			var failed []string
+			var errs ValidationErrors
+			if errors.As(err, &errs) {
+				for _, e := range errs {
+					failed = append(failed, e.Field+":"+e.Rule)
+				}
+			} else if err != nil {
+				t.Fatalf("ValidateVars = %v, want ValidationErrors", err)
+			}
+
+			if !reflect.DeepEqual(failed, tt.failed) {
+				t.Errorf("failures = %v, want %v", failed, tt.failed)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("vars with defaults = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type bookingPayload struct {
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+	EndsAt   time.Time `json:"ends_at" validate:"after_field=StartsAt"`
+	Deadline string    `json:"deadline" validate:"omitempty,iso8601,before_field=EndsAt"`
+}
+
+func TestDateRules(t *testing.T) {
+	checkRuleCases(t, New(), []ruleCase{
+		{"iso8601", "2025-06-01", true},
+		{"iso8601", "2025-06-01T10:30", true},
+		{"iso8601", "2025-06-01T10:30:00", true},
+		{"iso8601", "2025-06-01T10:30:00+02:00", true},
+		{"iso8601", "2025-06-01T10:30:00.123Z", true},
+		{"iso8601", "01/06/2025", false},
+		{"iso8601", "2025-13-01", false},
+		{"iso8601", time.Now(), true},
+		{"after=2025-01-01", "2025-06-01", true},
+		{"after=2025-01-01", "2025-01-01", false},
+		{"after=2025-01-01", time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), false},
+		{"before=2025-01-01", "2024-12-31T23:59:59Z", true},
+		{"after=now", time.Now().Add(time.Hour), true},
+		{"before=today", time.Now().Add(24 * time.Hour), false},
+		{"after=someday", "2025-06-01", false},
+		{"after=2025-01-01", 20250601, false},
+		// Malformed dates are left to the iso8601 rule
+		{"after=2025-01-01", "June 1st", true},
+	})
+
+	start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		payload bookingPayload
+		want    []string
+	}{
+		{"in order", bookingPayload{StartsAt: start, EndsAt: start.Add(time.Hour), Deadline: "2025-06-01T09:30:00Z"}, nil},
+		{"ends before start", bookingPayload{StartsAt: start, EndsAt: start.Add(-time.Hour)}, []string{"ends_at:after_field"}},
+		{"ends at start", bookingPayload{StartsAt: start, EndsAt: start}, []string{"ends_at:after_field"}},
+		{"deadline after end", bookingPayload{StartsAt: start, EndsAt: start.Add(time.Hour), Deadline: "2025-06-02"}, []string{"deadline:before_field"}},
+		{"malformed deadline", bookingPayload{StartsAt: start, EndsAt: start.Add(time.Hour), Deadline: "tomorrow"}, []string{"deadline:iso8601"}},
+		{"missing start", bookingPayload{EndsAt: start}, []string{"starts_at:required"}},
+	}
+
+	v := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failures(t, v, tt.payload); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failures = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}